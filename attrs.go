@@ -0,0 +1,65 @@
+package babylogger
+
+import "net/http"
+
+// WithResponseAttrs adds the attributes fn returns to each request's
+// response line. fn is called once the handler has returned, with the
+// request as the handler got it, so it can pick up anything the handler or
+// the middleware after the logger stored in its context. It's the hook for
+// integrations with routers and frameworks; see the chi sub-package for an
+// example. It can be given more than once to add several functions.
+func WithResponseAttrs(fn func(r *http.Request) []Attr) Option {
+	return func(l *Logger) {
+		l.attrFuncs = append(l.attrFuncs, fn)
+	}
+}
+
+// addResponseAttrs adds the attributes from the WithResponseAttrs functions.
+func (l *Logger) addResponseAttrs(r *http.Request, e *Entry) {
+	for _, fn := range l.attrFuncs {
+		e.Attrs = append(e.Attrs, fn(r)...)
+	}
+}
+
+// Response is what WithResponseBodyAttrs functions get to see of a
+// response.
+type Response struct {
+	Status    int
+	Header    http.Header
+	Body      []byte // the start of the body, up to the limit
+	Truncated bool   // whether the body was cut off at the limit
+}
+
+// WithResponseBodyAttrs adds the attributes fn returns to each request's
+// response line, like WithResponseAttrs, but fn also gets the response,
+// with up to maxBytes of its body. It's the hook for integrations that need
+// to look at what the handler sent; see the openapi sub-package for an
+// example.
+//
+// Bodies are copied as they're written, so this costs an allocation and a
+// copy per response. It can be given more than once to add several
+// functions, which then all get bodies up to the largest limit.
+func WithResponseBodyAttrs(maxBytes int, fn func(r *http.Request, res Response) []Attr) Option {
+	return func(l *Logger) {
+		l.bodyAttrFuncs = append(l.bodyAttrFuncs, fn)
+		if maxBytes > l.bodyAttrMax {
+			l.bodyAttrMax = maxBytes
+		}
+	}
+}
+
+// addResponseBodyAttrs adds the attributes from the WithResponseBodyAttrs
+// functions.
+func (l *Logger) addResponseBodyAttrs(r *http.Request, w *logWriter, e *Entry) {
+	w.bodyMtx.Lock()
+	res := Response{
+		Status:    w.code,
+		Header:    w.Header(),
+		Body:      w.inspect.buf.Bytes(),
+		Truncated: w.inspect.truncated,
+	}
+	w.bodyMtx.Unlock()
+	for _, fn := range l.bodyAttrFuncs {
+		e.Attrs = append(e.Attrs, fn(r, res)...)
+	}
+}
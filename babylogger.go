@@ -55,63 +55,188 @@ package babylogger
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"hash"
+	"io"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	humanize "github.com/dustin/go-humanize"
 )
 
-// Styles.
-var (
-	timeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "240"})
+// logWriter wraps a ResponseWriter and records what the handler does with it.
+//
+// Like any ResponseWriter it's meant to be used by one goroutine at a time,
+// but handlers sometimes get this wrong, e.g. by writing from a goroutine
+// while also calling WriteHeader, and the logger itself reads the writer from
+// other goroutines (see WithStreamingProgress). So that a misbehaving handler
+// can't corrupt the log:
+//
+//   - the status code is recorded once, by whichever of WriteHeader, Write
+//     or Flush comes first, and later calls to WriteHeader are dropped
+//   - the beforeHeader hook runs at most once
+//   - bytes, flushed, writeErr, hijack and unwrapped are only accessed
+//     atomically
+//   - the body captures and hash are updated under a lock
+//
+// None of this makes concurrent writes to the underlying ResponseWriter safe.
+type logWriter struct {
+	http.ResponseWriter
+	code        int   // set once, under headerOnce
+	bytes       int64 // accessed atomically
+	wroteHeader int32 // accessed atomically; set once code is recorded
+	flushed     int32 // accessed atomically; set once the handler flushes
+	writeErr    int32 // accessed atomically; set if a write fails
+	hijack      int32 // accessed atomically; set once the connection is hijacked
+	unwrapped   int32 // accessed atomically; set once Unwrap is called
 
-	uriStyle = timeStyle.Copy()
+	// doubleHeader is accessed atomically; it's set if WriteHeader is called
+	// once the header has been sent.
+	doubleHeader int32
 
-	methodStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "62", Dark: "62"})
+	headerOnce    sync.Once
+	firstByteOnce sync.Once
+	firstByteTime time.Time // when Write was first called
 
-	http200Style = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "35", Dark: "48"})
+	// bodyMtx guards capture, dump, inspect, bodyHash and inflate.
+	bodyMtx sync.Mutex
 
-	http300Style = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "208", Dark: "192"})
+	// capture, if set, copies the start of the body.
+	capture *bodyCapture
 
-	http400Style = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "39", Dark: "86"})
+	// dump, if set, copies the start of the body for
+	// BodyCapturingMiddleware.
+	dump *bodyDump
 
-	http500Style = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "203", Dark: "204"})
+	// inspect, if set, copies the start of the body for
+	// WithResponseBodyAttrs.
+	inspect *bodyDump
 
-	subtleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "250", Dark: "250"})
+	// bodyHash, if set, hashes everything written.
+	bodyHash hash.Hash
 
-	addressStyle = subtleStyle.Copy()
-)
+	// inflate, if set, counts the body's uncompressed bytes.
+	inflate *inflateCounter
 
-type logWriter struct {
-	http.ResponseWriter
-	code, bytes int
+	// beforeHeader, if set, is called once right before the header is sent,
+	// so last-minute headers can be added.
+	beforeHeader func(http.Header)
+
+	// informational, if set, is called for each informational 1xx response
+	// sent before the final one.
+	informational func(code int, h http.Header)
+
+	// onHijack, if set, wraps the connection handed out by Hijack.
+	onHijack func(net.Conn) net.Conn
+}
+
+// recordHeader records the status code, running the beforeHeader hook first.
+// Only the first call has any effect; it reports whether this was it.
+func (r *logWriter) recordHeader(code int) (first bool) {
+	r.headerOnce.Do(func() {
+		first = true
+		if r.beforeHeader != nil {
+			r.beforeHeader(r.Header())
+		}
+		if code != 0 {
+			r.code = code
+		}
+		atomic.StoreInt32(&r.wroteHeader, 1)
+	})
+	return first
+}
+
+// sendingHeader runs the beforeHeader hook if the header hasn't been sent
+// yet. The status stays at its default.
+func (r *logWriter) sendingHeader() {
+	r.recordHeader(0)
+}
+
+// headerWritten reports whether the header has been sent.
+func (r *logWriter) headerWritten() bool {
+	return atomic.LoadInt32(&r.wroteHeader) == 1
 }
 
 func (r *logWriter) Write(p []byte) (int, error) {
+	r.firstByteOnce.Do(func() {
+		r.firstByteTime = time.Now()
+	})
+	r.sendingHeader()
+	if r.capture != nil {
+		r.bodyMtx.Lock()
+		r.capture.write(r, p)
+		r.bodyMtx.Unlock()
+	}
+	if r.dump != nil {
+		r.bodyMtx.Lock()
+		r.dump.write(p)
+		r.bodyMtx.Unlock()
+	}
+	if r.inspect != nil {
+		r.bodyMtx.Lock()
+		r.inspect.write(p)
+		r.bodyMtx.Unlock()
+	}
 	written, err := r.ResponseWriter.Write(p)
-	r.bytes += written
+	atomic.AddInt64(&r.bytes, int64(written))
+	if r.bodyHash != nil {
+		r.bodyMtx.Lock()
+		r.bodyHash.Write(p[:written])
+		r.bodyMtx.Unlock()
+	}
+	if r.inflate != nil {
+		r.bodyMtx.Lock()
+		r.inflate.write(r.Header(), p[:written])
+		r.bodyMtx.Unlock()
+	}
+	if err != nil {
+		atomic.StoreInt32(&r.writeErr, 1)
+	}
 	return written, err
 }
 
 // Note this is generally only called when sending an HTTP error, so it's
 // important to set the `code` value to 200 as a default
+//
+// Only the first call counts, as HTTP allows only one status per response:
+// later calls are dropped rather than passed on. Informational 1xx statuses,
+// like 103 Early Hints, may precede the final one and are passed on without
+// being recorded, other than by the informational hook.
 func (r *logWriter) WriteHeader(code int) {
-	r.code = code
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		if r.informational != nil {
+			r.informational(code, r.Header())
+		}
+		r.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if !r.recordHeader(code) {
+		atomic.StoreInt32(&r.doubleHeader, 1)
+		return
+	}
 	r.ResponseWriter.WriteHeader(code)
 }
 
+// Flush exposes the underlying ResponseWriter Flusher implementation for
+// streaming responses
+func (r *logWriter) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.sendingHeader()
+		atomic.StoreInt32(&r.flushed, 1)
+		f.Flush()
+	}
+}
+
 // Hijack exposes the underlying ResponseWriter Hijacker implementation for
 // WebSocket compatibility
 func (r *logWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
@@ -119,73 +244,752 @@ func (r *logWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if !ok {
 		return nil, nil, fmt.Errorf("WebServer does not support hijacking")
 	}
-	return hj.Hijack()
+	conn, rw, err := hj.Hijack()
+	if err == nil {
+		atomic.StoreInt32(&r.hijack, 1)
+		if r.onHijack != nil {
+			conn = r.onHijack(conn)
+		}
+	}
+	return conn, rw, err
+}
+
+// hijacked reports whether the connection has been hijacked.
+func (r *logWriter) hijacked() bool {
+	return atomic.LoadInt32(&r.hijack) == 1
+}
+
+// SetReadDeadline exposes the underlying ResponseWriter's read deadline for
+// http.ResponseController
+func (r *logWriter) SetReadDeadline(deadline time.Time) error {
+	return http.NewResponseController(r.ResponseWriter).SetReadDeadline(deadline)
+}
+
+// SetWriteDeadline exposes the underlying ResponseWriter's write deadline
+// for http.ResponseController
+func (r *logWriter) SetWriteDeadline(deadline time.Time) error {
+	return http.NewResponseController(r.ResponseWriter).SetWriteDeadline(deadline)
+}
+
+// EnableFullDuplex exposes the underlying ResponseWriter's full duplex mode
+// for http.ResponseController
+func (r *logWriter) EnableFullDuplex() error {
+	return http.NewResponseController(r.ResponseWriter).EnableFullDuplex()
+}
+
+// Unwrap returns the underlying ResponseWriter, so http.ResponseController
+// can reach any other methods it implements
+func (r *logWriter) Unwrap() http.ResponseWriter {
+	atomic.StoreInt32(&r.unwrapped, 1)
+	return r.ResponseWriter
+}
+
+// Logger is a configurable HTTP logging middleware. Create one with New. The
+// package-level Middleware function uses a Logger with the default options.
+//
+// Options are fixed once the Logger is created, except for a few that can be
+// changed while it's serving requests, for example to turn up logging while
+// debugging an issue in production: colors, the minimum status, the sampler
+// and the skipped paths. See SetNoColor, SetMinStatus, SetSampler and
+// SetSkipPaths. Changes apply to requests that start after them.
+type Logger struct {
+	// Counters, accessed atomically. These are kept at the top of the struct
+	// so they're 64-bit aligned on 32-bit platforms.
+	requests int64
+	bytes    int64
+	inFlight int64
+	dropped  int64
+	classes  [6]int64 // indexed by status code / 100
+
+	requestTimeout       func(*http.Request) time.Duration
+	noRateLimitHighlight bool
+	entryWriters         []EntryWriter
+	blocklist            atomic.Value // *ipTrie
+	logDecider           func(Entry) bool
+	format               Format
+	panicRecovery        bool
+	panicDetails         bool
+	rateLimiter          RateLimiter
+	slog                 *slog.Logger
+	contextLogger        func(context.Context) *slog.Logger
+	childLogger          func(context.Context, *slog.Logger) *slog.Logger
+	theme                Theme
+	localAddr            bool
+	remoteUser           bool
+	fieldNames           FieldNames
+	out                  io.Writer
+	logger               *log.Logger
+	plain                Theme // theme, without colors
+	methodColors         map[string]lipgloss.TerminalColor
+	handler              http.Handler
+	durationScale        *[2]time.Duration // low, high
+	progressInterval     time.Duration
+	progressThreshold    int64
+	progressFlushed      bool // only report progress for flushed responses
+	serverTiming         bool
+	correlationHeader    string
+	debugHeader          string
+	debugValue           []byte
+	health               *healthChecks
+	shadow               http.Handler
+	shadowHeaders        []string
+	shadowBodies         bool
+	shadowSem            chan struct{}
+	fieldOrder           []Field
+	trustProxy           bool
+	requestID            func() string
+	snowflake            *snowflake
+	goroutineCount       bool
+	leakThreshold        int
+	leakAlert            func(*http.Request, int)
+	logTimeout           time.Duration
+	timeoutOut           *timeoutWriter
+	breaker              *breaker
+	staticExts           map[string]bool
+	replayWindow         time.Duration
+	replayStore          ReplayStore
+	replayFingerprint    func(*http.Request) string
+	stdRoutePattern      bool
+	pathTemplate         func(string) string
+	connectLogging       bool
+	location             *time.Location
+	respJSONFields       []string
+	respJSONMaxBytes     int
+	errSink              *errorSink
+	requestLogger        bool
+	gcpProject           string
+	minimal              bool
+	statusGlyph          bool
+	queryCounter         QueryCounter
+	queryMax             int
+	queryAlert           func(*http.Request, int)
+	buildInfo            bool
+	classify             func(int) StatusClass
+	settings             liveConfig   // initial live settings, set by options
+	live                 atomic.Value // *liveConfig
+	liveMtx              sync.Mutex
+	ttfb                 bool
+	clockSkew            bool
+	bypass               map[string]bool
+	lengthFallback       bool
+	upgradeLogging       bool
+	delay                time.Duration
+	jsonEscapeHTML       bool
+	durationUnit         DurationUnit
+	statusText           func(int) string
+	trimSlash            bool
+	skipKey              interface{}
+	memStats             bool
+	bufferInterval       time.Duration
+	buffered             *bufferedWriter
+	cors                 bool
+	recent               outcomes // for ErrorRate
+	attrFuncs            []func(*http.Request) []Attr
+	methodStyles         map[string]lipgloss.Style
+	plainMethodStyles    map[string]lipgloss.Style
+	detectStatus         bool
+	ema                  *latencyEMA
+	accessTemplate       string
+	accessTmpl           *template.Template
+	queryAllow           map[string]bool
+	outColorless         bool // whether out can't show colors
+	panicResponse        *panicResponse
+	formFields           bool
+	tracing              *tracingSampler
+	bodyDumpLimit        int
+	writerPool           bool
+	ctxFields            []ContextExtractor
+	rangeInfo            bool
+	uncompressed         bool
+	connID               bool
+	excludeStatus        map[int]bool
+	informational        bool
+	webSocketMode        bool
+	bodyAttrFuncs        []func(*http.Request, Response) []Attr
+	bodyAttrMax          int
+	maxClockSkew         time.Duration
+
+	mtx sync.Mutex // guards writes of structured lines
+}
+
+// Option is a functional option for configuring a Logger.
+type Option func(*Logger)
+
+// New returns a Logger configured with the given options. It panics if the
+// options are invalid.
+func New(opts ...Option) *Logger {
+	l := &Logger{
+		theme:      DefaultTheme(),
+		fieldNames: DefaultFieldNames(),
+		fieldOrder: DefaultFieldOrder(),
+		classify:   DefaultStatusClassifier,
+		statusText: http.StatusText,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.out != nil {
+		l.outColorless = isColorless(l.out)
+	}
+	l.plain = plainTheme(l.theme)
+	l.plainMethodStyles = plainMethodStyles(l.methodStyles)
+	if l.shadow != nil {
+		l.shadowSem = make(chan struct{}, maxShadows)
+	}
+	settings := l.settings
+	l.live.Store(&settings)
+	if err := l.validate(); err != nil {
+		panic("babylogger: " + err.Error())
+	}
+	if l.health != nil {
+		go l.summarizeHealthChecks()
+	}
+	if l.bufferInterval > 0 {
+		l.startBuffering()
+	}
+	if l.logTimeout > 0 {
+		l.startLogTimeout()
+	}
+	return l
 }
 
+// validate checks the Logger's configuration.
+func (l *Logger) validate() error {
+	if l.health != nil {
+		if err := l.health.validate(); err != nil {
+			return err
+		}
+	}
+	if l.snowflake != nil {
+		if err := l.snowflake.validate(); err != nil {
+			return err
+		}
+	}
+	if l.breaker != nil {
+		if err := l.breaker.validate(); err != nil {
+			return err
+		}
+	}
+	if l.ema != nil {
+		if err := l.ema.validate(); err != nil {
+			return err
+		}
+	}
+	if l.accessTemplate != "" {
+		if err := l.parseAccessTemplate(); err != nil {
+			return err
+		}
+	}
+	if err := validateFieldOrder(l.fieldOrder); err != nil {
+		return err
+	}
+	return l.fieldNames.validate()
+}
+
+// std is the Logger used by the package-level Middleware function.
+var std = New()
+
 // Middleware is the logging middleware where we log incoming and outgoing
 // requests for a multiplexer. It should be the first middleware called so it
 // can log request times accurately.
 func Middleware(next http.Handler) http.Handler {
+	return std.Middleware(next)
+}
+
+// Middleware returns the logging middleware for this Logger. Like the
+// package-level Middleware it should be the first middleware called.
+func (l *Logger) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r != nil && l.bypassed(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		l.serve(w, r, next)
+	})
+}
+
+func (l *Logger) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	addr := r.RemoteAddr
+	if conn, ok := connAddrsFromContext(r.Context()); ok && conn.remote != nil {
+		addr = conn.remote.String()
+	}
+	if colon := strings.LastIndex(addr, ":"); colon != -1 {
+		addr = addr[:colon]
+	}
+
+	e := &Entry{
+		Time:       l.now(),
+		Method:     r.Method,
+		URI:        l.loggedURI(r.RequestURI),
+		Path:       r.URL.Path,
+		Proto:      r.Proto,
+		RemoteAddr: addr,
+		ctx:        r.Context(),
+	}
+
+	if l.trustProxy && r != nil {
+		addForwarded(r, e)
+	}
+
+	if l.format == GCP && r != nil {
+		e.gcp = readGCPTrace(r)
+	}
+	if l.format == Nginx && r != nil {
+		e.nginx = &nginxRequest{userAgent: r.UserAgent(), referer: r.Referer()}
+	}
+
+	if l.localAddr && r != nil {
+		addLocalAddr(r, e)
+	}
+
+	if l.connID && r != nil {
+		addConnID(r, e)
+	}
+
+	if l.remoteUser && r != nil {
+		addRemoteUser(r, e)
+	}
+
+	if l.clockSkew && r != nil {
+		l.addClockSkew(r, e)
+	}
+
+	if l.cors && r != nil {
+		addCORS(r, e)
+	}
+
+	if l.formFields && r != nil {
+		addFormFields(r, e)
+	}
+
+	var ctxFields []Attr
+	if len(l.ctxFields) > 0 && r != nil {
+		ctxFields = l.addContextFields(r, e)
+	}
+
+	debug := r != nil && l.debugTriggered(r)
+	if debug {
+		l.addDebugDetails(r, e)
+	}
+
+	if l.requestID != nil && r != nil {
+		r = l.addRequestID(w, r, e)
+	}
+
+	if l.correlationHeader != "" && r != nil {
+		r = l.correlate(w, r, e)
+	}
+
+	if l.tracing != nil && r != nil && !debug {
+		r = l.tracing.sample(r, e)
+	}
+
+	if l.childLogger != nil && r != nil {
+		r = l.addChildLogger(r, e)
+	}
 
-		addr := r.RemoteAddr
-		if colon := strings.LastIndex(addr, ":"); colon != -1 {
-			addr = addr[:colon]
+	// Per-request timeout
+	var timeout context.Context
+	if l.requestTimeout != nil && r != nil {
+		if d := l.requestTimeout(r); d > 0 {
+			var cancel context.CancelFunc
+			timeout, cancel = context.WithTimeout(r.Context(), d)
+			defer cancel()
+			r = r.WithContext(timeout)
+			e.ctx = timeout
+			e.add("timeout_applied", d)
 		}
+	}
+
+	match, blocked := l.blocked(e.RemoteAddr)
+	if blocked {
+		e.add("blocked", true)
+		e.add("blocklist_match", match)
+	}
 
-		arrow := subtleStyle.Render("<-")
-		method := methodStyle.Render(r.Method)
-		uri := uriStyle.Render(r.RequestURI)
-		address := addressStyle.Render(addr)
+	limited := !blocked && l.checkRateLimit(r, e)
 
-		// Log request
-		log.Printf("%s %s %s %s", arrow, method, uri, address)
+	connect := l.connectLogging && r != nil && r.Method == http.MethodConnect
+	if connect {
+		e.add("connect_target", r.Host)
+	}
+	replayed := !blocked && !limited && l.replayStore != nil && r != nil && l.checkReplay(r, e)
 
-		writer := &logWriter{
-			ResponseWriter: w,
-			code:           http.StatusOK, // default. so important! see above.
+	// Circuit breaker
+	tripped := false
+	if l.breaker != nil && !blocked && !limited && !replayed && r != nil {
+		ok, changed := l.breaker.allow(time.Now())
+		if changed != "" {
+			l.logCircuit(changed, 0)
 		}
+		if tripped = !ok; tripped {
+			e.add("circuit_state", circuitOpen)
+		}
+	}
+	recorded := false
+	if l.breaker != nil && !blocked && !limited && !replayed && !tripped && r != nil {
+		// A handler that panics without recovery never gets its status
+		// recorded; count it as a failure so it doesn't hold on to a
+		// half-open slot forever
+		defer func() {
+			if !recorded {
+				if changed, rate := l.breaker.record(time.Now(), http.StatusInternalServerError); changed != "" {
+					l.logCircuit(changed, rate)
+				}
+			}
+		}()
+	}
 
-		arrow = subtleStyle.Render("->")
-		startTime := time.Now()
+	// Everything added to the entry so far belongs on the request line
+	e.split = len(e.Attrs)
 
-		// Not sure why the request could possibly be nil, but it has happened
-		if r == nil {
-			http.Error(w, http.StatusText(http.StatusInternalServerError),
-				http.StatusInternalServerError)
-			writer.code = http.StatusInternalServerError
+	// Log request. If there's a log decider, a sampler or excluded statuses,
+	// or the request is for a static asset, the request line is held back
+	// until we know whether the response will be logged. Health checks are
+	// only logged in summaries. Debug requests are always logged.
+	cfg := l.config()
+	decide := (l.logDecider != nil || cfg.sampler != nil || cfg.minStatus > 0 ||
+		l.isStatic(e.Path) || e.unsampled || len(l.excludeStatus) > 0) && !debug
+	quiet := !debug && l.health.match(e.Path)
+	skip := !debug && (cfg.skipPaths[e.Path] || l.skippedByContext(r.Context()))
+	if !decide && !quiet && !skip {
+		l.logRequest(e)
+	}
+
+	writer := l.newLogWriter(w)
+
+	if len(l.respJSONFields) > 0 {
+		writer.capture = &bodyCapture{max: l.respJSONMaxBytes}
+	}
+	if r != nil {
+		writer.dump = l.newBodyDump(r)
+	}
+	if len(l.bodyAttrFuncs) > 0 {
+		writer.inspect = &bodyDump{max: l.bodyAttrMax}
+	}
+	if l.uncompressed {
+		inflate := &inflateCounter{}
+		writer.inflate = inflate
+		// Don't leave the decompressor waiting if the handler panics
+		defer inflate.stop()
+	}
+	if l.informational && !quiet && !skip {
+		writer.informational = l.informationalHook(e)
+	}
+
+	startTime := time.Now()
+
+	if l.delay > 0 {
+		next = delayed(next, l.delay)
+	}
+	if l.serverTiming {
+		writer.beforeHeader = serverTimingHook(startTime)
+	}
+	if l.webSocketMode && !quiet && !skip {
+		writer.onHijack = l.sessionHook(e, startTime)
+	}
+
+	atomic.AddInt64(&l.inFlight, 1)
+	defer atomic.AddInt64(&l.inFlight, -1)
+
+	var abort interface{} // a recovered http.ErrAbortHandler panic
+
+	var shadow <-chan shadowResponse
+
+	if l.queryCounter != nil {
+		l.queryCounter.Reset()
+	}
+
+	var goroutines int
+	if l.countGoroutines() {
+		goroutines = runtime.NumGoroutine()
+	}
+
+	var mem memSnapshot
+	if l.memStats {
+		mem = readMemStats()
+	}
+
+	var stopProgress func() bool
+	if l.progressInterval > 0 && r != nil {
+		stopProgress = l.watchProgress(r.Context(), writer, e, startTime)
+		// Stop watching even if the handler panics
+		defer stopProgress()
+	}
+
+	// Not sure why the request could possibly be nil, but it has happened
+	if r == nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		writer.code = http.StatusInternalServerError
+	} else if blocked {
+		http.Error(writer, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	} else if limited {
+		writer.Header().Set("Retry-After", e.retryAfter)
+		http.Error(writer, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	} else if replayed {
+		http.Error(writer, http.StatusText(http.StatusConflict), http.StatusConflict)
+	} else if tripped {
+		http.Error(writer, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	} else if connect {
+		l.serveConnect(writer, r, e)
+	} else {
+		if l.shadow != nil {
+			r, shadow = l.startShadow(writer, r)
+		}
+		if l.panicRecovery {
+			abort = l.serveRecover(writer, r, next, e)
 		} else {
 			next.ServeHTTP(writer, r)
 		}
+	}
+
+	if l.detectStatus {
+		detectStatus(writer, e)
+	}
+
+	// If the handler didn't write anything, the header is sent after we
+	// return, so there's still time for last-minute headers
+	writer.sendingHeader()
+
+	e.Duration = time.Now().Sub(startTime)
+
+	if stopProgress != nil && stopProgress() {
+		e.add("complete", streamComplete(writer, r))
+	}
+
+	if l.countGoroutines() && r != nil {
+		// A shadow handler that's still running is ours, not a leak
+		if shadow != nil && len(shadow) == 0 {
+			goroutines++
+		}
+		l.checkGoroutines(r, e, goroutines)
+	}
+
+	if l.queryCounter != nil && r != nil {
+		l.countQueries(r, e)
+	}
+
+	if l.memStats {
+		addMemStats(e, mem)
+	}
+
+	if l.ttfb && !writer.firstByteTime.IsZero() {
+		e.add("ttfb", writer.firstByteTime.Sub(startTime))
+		e.add("total", e.Duration)
+	}
+
+	if l.ema != nil && r != nil {
+		l.ema.observe(e)
+	}
+
+	if writer.capture != nil && writer.capture.buf != nil {
+		l.addResponseJSONFields(writer.capture.buf.Bytes(), e)
+	}
+
+	if l.buildInfo {
+		e.Attrs = append(e.Attrs, buildAttrs...)
+	}
+
+	if l.logsRoute() && r != nil {
+		e.add("route", l.route(r, e))
+	}
+
+	if len(l.ctxFields) > 0 && r != nil {
+		l.addChangedContextFields(r, e, ctxFields)
+	}
+
+	if len(l.attrFuncs) > 0 && r != nil {
+		l.addResponseAttrs(r, e)
+	}
+	if writer.inspect != nil && r != nil {
+		l.addResponseBodyAttrs(r, writer, e)
+	}
+
+	if l.upgradeLogging && r != nil {
+		addUpgrade(r, writer, e)
+	}
+
+	if l.rangeInfo && r != nil {
+		addRange(r, writer, e)
+	}
+
+	if debug && atomic.LoadInt32(&writer.doubleHeader) == 1 {
+		e.add("double_write_header", true)
+	}
+
+	e.Status = writer.code
+	e.Bytes = int(atomic.LoadInt64(&writer.bytes))
+	if l.lengthFallback && e.Bytes == 0 {
+		if n, ok := contentLength(e.Method, writer); ok {
+			e.Bytes = n
+			e.add("bytes_from_header", true)
+		}
+	}
+	if writer.inflate != nil {
+		writer.bodyMtx.Lock()
+		n, ok := writer.inflate.finish()
+		writer.bodyMtx.Unlock()
+		if ok {
+			e.add("compressed_bytes", e.Bytes)
+			e.Bytes = int(n)
+		}
+	}
+	l.count(e.Status, e.Bytes)
 
-		elapsedTime := time.Now().Sub(startTime)
+	if writer.dump != nil {
+		l.addBodyDump(writer.dump, e)
+	}
 
-		var statusStyle lipgloss.Style
+	if l.breaker != nil && !blocked && !limited && !replayed && !tripped && r != nil {
+		recorded = true
+		if changed, rate := l.breaker.record(time.Now(), e.Status); changed != "" {
+			l.logCircuit(changed, rate)
+		}
+	}
 
-		if writer.code < 300 { // 200s
-			statusStyle = http200Style
-		} else if writer.code < 400 { // 300s
-			statusStyle = http300Style
-		} else if writer.code < 500 { // 400s
-			statusStyle = http400Style
-		} else { // 500s
-			statusStyle = http500Style
+	if timeout != nil && timeout.Err() == context.DeadlineExceeded {
+		e.add("timed_out", true)
+	}
+
+	if l.rateLimited(e.Status) && !e.has("retry_after") {
+		if after := writer.Header().Get("Retry-After"); after != "" {
+			e.add("retry_after", after)
 		}
+	}
 
-		status := statusStyle.Render(fmt.Sprintf("%d %s", writer.code, http.StatusText(writer.code)))
+	// Log response
+	if quiet {
+		l.health.record(e.Path, e.Status)
+	} else if skip {
+		// Not logged
+	} else if !decide {
+		l.logResponse(e)
+	} else if l.shouldLog(cfg, r, e) {
+		l.logRequest(e)
+		l.logResponse(e)
+	}
 
-		// The excellent humanize package adds a space between the integer and
-		// the unit as far as bytes are conerned (105 B). In our case that
-		// makes it a little harder on the eyes when scanning the logs, so
-		// we're stripping that space
-		formattedBytes := strings.Replace(
-			humanize.Bytes(uint64(writer.bytes)),
-			" ", "", 1)
+	l.writeEntry(e)
 
-		bytes := subtleStyle.Render(formattedBytes)
-		time := timeStyle.Render(fmt.Sprintf("%s", elapsedTime))
+	if shadow != nil {
+		primary := shadowResponse{
+			status: e.Status,
+			bytes:  int64(e.Bytes),
+			header: writer.Header().Clone(),
+		}
+		if writer.bodyHash != nil {
+			primary.sum = writer.bodyHash.Sum(nil)
+		}
+		ec := *e
+		go l.compareShadow(&ec, primary, shadow)
+	}
 
-		// Log response
-		log.Printf("%s %s %s %v", arrow, status, bytes, time)
+	l.releaseLogWriter(writer)
+
+	if abort != nil {
+		panic(abort)
+	}
+}
+
+// logRequest logs the incoming request line. Only the pretty format has one;
+// the others log a single line per request in logResponse.
+func (l *Logger) logRequest(e *Entry) {
+	if l.format != Pretty || l.minimal || l.accessTmpl != nil || l.slogger(e) != nil {
+		return
+	}
+
+	l.print(l.requestLine(e))
+}
+
+// requestLine renders the pretty request line for an entry.
+func (l *Logger) requestLine(e *Entry) string {
+	t := l.styles()
+	arrow := t.Subtle.Render("<-")
+	cols := l.columns(map[Field]string{
+		FieldMethod:     l.methodStyle(t, e.Method).Render(e.Method),
+		FieldURI:        t.URI.Render(e.URI),
+		FieldRemoteAddr: t.Address.Render(e.RemoteAddr),
+	})
+
+	return strings.Join(append([]string{arrow}, cols...), " ") + l.renderAttrs(e.requestAttrs())
+}
+
+// logResponse logs the outgoing response line.
+func (l *Logger) logResponse(e *Entry) {
+	if logger := l.slogger(e); logger != nil {
+		l.logSlog(logger, e)
+		return
+	}
+	defer l.printBodyDump(e)
+
+	switch l.format {
+	case JSON:
+		l.logJSON(e)
+		return
+	case ECS:
+		l.logECS(e)
+		return
+	case GCP:
+		l.logGCP(e)
+		return
+	case Nginx:
+		l.logNginx(e)
+		return
+	}
+
+	if l.minimal {
+		l.printStatus(e.Status, l.minimalLine(e))
+		return
+	}
+
+	if l.accessTmpl != nil {
+		l.printStatus(e.Status, l.templateLine(e))
+		return
+	}
+
+	l.printStatus(e.Status, l.responseLine(e))
+
+	for _, a := range e.responseAttrs() {
+		if p, ok := a.Value.(Panic); ok {
+			for _, f := range p.Frames {
+				l.printStatus(e.Status, l.styles().Subtle.Render("   at "+f.String()))
+			}
+		}
+	}
+}
+
+// responseLine renders the pretty response line for an entry.
+func (l *Logger) responseLine(e *Entry) string {
+	t := l.styles()
+	arrow := t.Subtle.Render("->")
+	status := l.renderStatus(e.Status)
+
+	// The excellent humanize package adds a space between the integer and
+	// the unit as far as bytes are conerned (105 B). In our case that
+	// makes it a little harder on the eyes when scanning the logs, so
+	// we're stripping that space
+	formattedBytes := strings.Replace(
+		humanize.Bytes(uint64(e.Bytes)),
+		" ", "", 1)
+
+	cols := l.columns(map[Field]string{
+		FieldStatus:   status,
+		FieldBytes:    t.Bytes.Render(formattedBytes),
+		FieldDuration: l.durationStyle(t, e.Duration).Render(fmt.Sprintf("%s", e.Duration)),
 	})
+
+	return strings.Join(append([]string{arrow}, cols...), " ") + l.renderAttrs(e.responseAttrs())
+}
+
+// renderAttrs formats attributes as space-separated key=value pairs, with a
+// leading space so it can be tacked onto the end of a line.
+func (l *Logger) renderAttrs(attrs []Attr) string {
+	t := l.styles()
+	var b strings.Builder
+	for _, a := range attrs {
+		b.WriteString(" ")
+		b.WriteString(t.Subtle.Render(a.String()))
+	}
+	return b.String()
 }
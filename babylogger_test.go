@@ -0,0 +1,138 @@
+package babylogger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer that's safe for concurrent use, for
+// capturing output written from several goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newTestLogger returns a Logger writing uncolored lines to the returned
+// buffer.
+func newTestLogger(opts ...Option) (*Logger, *syncBuffer) {
+	out := new(syncBuffer)
+	opts = append([]Option{WithOutput(out), WithNoColor()}, opts...)
+	return New(opts...), out
+}
+
+// serveTest serves r with h behind l's middleware.
+func serveTest(l *Logger, h http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	l.Middleware(h).ServeHTTP(w, r)
+	return w
+}
+
+// lines returns the non-empty lines of the output.
+func lines(out *syncBuffer) []string {
+	var ls []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line != "" {
+			ls = append(ls, line)
+		}
+	}
+	return ls
+}
+
+// entryWriterFunc is an EntryWriter calling a function.
+type entryWriterFunc func(Entry) error
+
+func (f entryWriterFunc) WriteEntry(e Entry) error {
+	return f(e)
+}
+
+// TestLogWriterConcurrentWrites writes to the same logWriter from several
+// goroutines. Run it with -race.
+func TestLogWriterConcurrentWrites(t *testing.T) {
+	const goroutines, writes = 8, 100
+	chunk := []byte("0123456789")
+
+	rec := &lockedRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w := &logWriter{ResponseWriter: rec, code: http.StatusOK}
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w.WriteHeader(http.StatusAccepted + i)
+			for j := 0; j < writes; j++ {
+				w.Write(chunk)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if want := int64(goroutines * writes * len(chunk)); w.bytes != want {
+		t.Errorf("counted %d bytes, want %d", w.bytes, want)
+	}
+	if rec.Body.Len() != goroutines*writes*len(chunk) {
+		t.Errorf("wrote %d bytes, want %d", rec.Body.Len(), goroutines*writes*len(chunk))
+	}
+	if w.code != rec.Code {
+		t.Errorf("recorded status %d, but %d was sent", w.code, rec.Code)
+	}
+}
+
+// lockedRecorder is a ResponseRecorder that's safe for concurrent use.
+type lockedRecorder struct {
+	mu sync.Mutex
+	*httptest.ResponseRecorder
+}
+
+func (r *lockedRecorder) WriteHeader(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ResponseRecorder.WriteHeader(code)
+}
+
+func (r *lockedRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ResponseRecorder.Write(p)
+}
+
+func TestDoubleWriteHeader(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		var entry Entry
+		l, _ := newTestLogger(WithDebugTrigger("X-Debug", "secret"), WithEntryWriter(entryWriterFunc(func(e Entry) error {
+			entry = e
+			return nil
+		})))
+		r := httptest.NewRequest("GET", "/", nil)
+		if debug {
+			r.Header.Set("X-Debug", "secret")
+		}
+		w := serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.WriteHeader(http.StatusInternalServerError)
+		}, r)
+
+		if entry.Status != http.StatusOK || w.Code != http.StatusOK {
+			t.Errorf("debug %v: logged %d and sent %d, want 200", debug, entry.Status, w.Code)
+		}
+		if got := entry.has("double_write_header"); got != debug {
+			t.Errorf("debug %v: double_write_header is %v", debug, got)
+		}
+	}
+}
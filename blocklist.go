@@ -0,0 +1,121 @@
+package babylogger
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// WithIPBlocklist rejects requests from clients in any of the given CIDR
+// ranges (plain IP addresses are also accepted). Blocked requests get a 403
+// Forbidden response without reaching the next handler, and are logged with
+// blocked=true and the range that matched, e.g. blocklist_match=10.0.0.0/8.
+//
+// The ranges are parsed when the option is applied; it panics if any of them
+// are invalid. Use Logger.ReloadBlocklist to replace the blocklist at
+// runtime.
+func WithIPBlocklist(cidrs ...string) Option {
+	t, err := newIPTrie(cidrs)
+	if err != nil {
+		panic("babylogger: " + err.Error())
+	}
+	return func(l *Logger) {
+		l.blocklist.Store(t)
+	}
+}
+
+// ReloadBlocklist atomically replaces the Logger's IP blocklist. Requests
+// already in flight are unaffected. If any of the ranges are invalid an error
+// is returned and the current blocklist is kept. Calling it with no ranges
+// clears the blocklist.
+func (l *Logger) ReloadBlocklist(cidrs ...string) error {
+	t, err := newIPTrie(cidrs)
+	if err != nil {
+		return err
+	}
+	l.blocklist.Store(t)
+	return nil
+}
+
+// blocked returns the blocklist range matching the given client address, if
+// any.
+func (l *Logger) blocked(addr string) (string, bool) {
+	t, _ := l.blocklist.Load().(*ipTrie)
+	if t == nil {
+		return "", false
+	}
+	ip := net.ParseIP(strings.Trim(addr, "[]"))
+	if ip == nil {
+		return "", false
+	}
+	return t.lookup(ip)
+}
+
+// ipTrie is a binary prefix trie of IP ranges. IPv4 ranges are stored in
+// their IPv4-in-IPv6 form so both families can share the same tree.
+type ipTrie struct {
+	root ipTrieNode
+}
+
+type ipTrieNode struct {
+	children [2]*ipTrieNode
+	prefix   string // set if a range ends at this node
+}
+
+func newIPTrie(cidrs []string) (*ipTrie, error) {
+	t := &ipTrie{}
+	for _, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			if ip := net.ParseIP(c); ip != nil && ip.To4() != nil {
+				c += "/32"
+			} else {
+				c += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blocklist entry %q: %v", c, err)
+		}
+		t.insert(n)
+	}
+	return t, nil
+}
+
+func (t *ipTrie) insert(n *net.IPNet) {
+	ones, bits := n.Mask.Size()
+	if bits == 32 {
+		ones += 96
+	}
+	ip := n.IP.To16()
+
+	node := &t.root
+	for i := 0; i < ones; i++ {
+		b := bit(ip, i)
+		if node.children[b] == nil {
+			node.children[b] = &ipTrieNode{}
+		}
+		node = node.children[b]
+	}
+	node.prefix = n.String()
+}
+
+// lookup returns the most specific range containing ip.
+func (t *ipTrie) lookup(ip net.IP) (match string, ok bool) {
+	ip = ip.To16()
+	node := &t.root
+	for i := 0; node != nil; i++ {
+		if node.prefix != "" {
+			match, ok = node.prefix, true
+		}
+		if i == len(ip)*8 {
+			break
+		}
+		node = node.children[bit(ip, i)]
+	}
+	return match, ok
+}
+
+// bit returns the i-th most significant bit of ip.
+func bit(ip net.IP, i int) int {
+	return int(ip[i/8]>>(7-uint(i%8))) & 1
+}
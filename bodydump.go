@@ -0,0 +1,120 @@
+package babylogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+// defaultBodyDumpLimit is how much of a response body BodyCapturingMiddleware
+// captures by default.
+const defaultBodyDumpLimit = 64 << 10
+
+type bodyDumpKey struct{}
+
+// BodyCapturingMiddleware is like Middleware, but for responses whose status
+// filter returns true it also logs the response body, as a hex dump like
+// hexdump -C prints. It's meant for debugging binary protocols and
+// corrupted responses, and uses the default Logger. See
+// Logger.BodyCapturingMiddleware.
+func BodyCapturingMiddleware(next http.Handler, filter func(status int) bool) http.Handler {
+	return std.BodyCapturingMiddleware(next, filter)
+}
+
+// BodyCapturingMiddleware is like Middleware, but for responses whose status
+// filter returns true it also logs the response body, as a hex dump like
+// hexdump -C prints. In the pretty format the dump is logged below the
+// response line, as a block of its own:
+//
+//	-> 502 Bad Gateway 24B 1.2ms
+//	--- response body, 24 bytes ---
+//	00000000  7b 22 65 72 72 6f 72 22  3a 22 62 61 64 20 67 61  |{"error":"bad ga|
+//	00000010  74 65 77 61 79 22 7d 0a                           |teway"}.|
+//	--- end of response body ---
+//
+// In the other formats it's logged as response_body_hex. Only the first
+// 64KB of the body are captured, which can be changed with
+// WithBodyCaptureLimit; the dump of a longer body ends with a note that it
+// was truncated.
+func (l *Logger) BodyCapturingMiddleware(next http.Handler, filter func(status int) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r != nil {
+			ctx := context.WithValue(r.Context(), bodyDumpKey{}, filter)
+			r = r.WithContext(ctx)
+		}
+		l.Middleware(next).ServeHTTP(w, r)
+	})
+}
+
+// WithBodyCaptureLimit sets how much of a response body
+// BodyCapturingMiddleware captures. The default is 64KB.
+func WithBodyCaptureLimit(n int) Option {
+	return func(l *Logger) {
+		l.bodyDumpLimit = n
+	}
+}
+
+// bodyDump captures the start of a response body, for
+// BodyCapturingMiddleware and WithResponseBodyAttrs.
+type bodyDump struct {
+	filter    func(status int) bool
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// newBodyDump returns a bodyDump if the request came through
+// BodyCapturingMiddleware.
+func (l *Logger) newBodyDump(r *http.Request) *bodyDump {
+	filter, ok := r.Context().Value(bodyDumpKey{}).(func(int) bool)
+	if !ok || filter == nil {
+		return nil
+	}
+	max := l.bodyDumpLimit
+	if max <= 0 {
+		max = defaultBodyDumpLimit
+	}
+	return &bodyDump{filter: filter, max: max}
+}
+
+func (d *bodyDump) write(p []byte) {
+	if room := d.max - d.buf.Len(); len(p) > room {
+		p = p[:room]
+		d.truncated = true
+	}
+	d.buf.Write(p)
+}
+
+// String returns the hex dump.
+func (d *bodyDump) String() string {
+	s := hex.Dump(d.buf.Bytes())
+	if d.truncated {
+		s += fmt.Sprintf("(truncated after %d bytes)\n", d.max)
+	}
+	return s
+}
+
+// addBodyDump adds the captured body to the entry, if the status calls for
+// it.
+func (l *Logger) addBodyDump(d *bodyDump, e *Entry) {
+	if !d.filter(e.Status) {
+		return
+	}
+	if l.format == Pretty && l.slogger(e) == nil {
+		e.bodyDump = d
+		return
+	}
+	e.add("response_body_hex", d.String())
+}
+
+// printBodyDump logs the captured body below the response line.
+func (l *Logger) printBodyDump(e *Entry) {
+	if e.bodyDump == nil {
+		return
+	}
+	block := fmt.Sprintf("--- response body, %d bytes ---\n%s--- end of response body ---\n",
+		e.Bytes, e.bodyDump.String())
+	l.writeFor(e.Status, []byte(block))
+}
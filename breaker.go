@@ -0,0 +1,172 @@
+package babylogger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states.
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// circuitBuckets is how many buckets the circuit breaker's window is split
+// into to compute a sliding error rate.
+const circuitBuckets = 10
+
+// minCircuitRequests is how many requests a window needs before the circuit
+// can open, so a single failure doesn't trip it on a quiet server.
+const minCircuitRequests = 10
+
+// WithCircuitBreaker stops calling the handler when it's failing, giving it
+// room to recover. When the share of 5xx responses within window reaches
+// threshold (between 0 and 1), the circuit opens: requests get a 503 Service
+// Unavailable without reaching the handler and are logged with
+// circuit_state=open. After window has passed again, halfOpenRequests
+// requests are let through. If they all succeed the circuit closes, and if
+// any fails it opens again.
+//
+// State changes are logged as circuit_breaker events, like:
+//
+//	~> circuit_breaker circuit_state=open error_rate=0.62
+//
+// The circuit doesn't open before a window has seen at least 10 requests.
+// New panics if threshold isn't between 0 and 1, window isn't positive or
+// halfOpenRequests is less than 1.
+func WithCircuitBreaker(threshold float64, window time.Duration, halfOpenRequests int) Option {
+	return func(l *Logger) {
+		l.breaker = &breaker{
+			threshold: threshold,
+			window:    window,
+			halfOpen:  halfOpenRequests,
+			state:     circuitClosed,
+		}
+	}
+}
+
+type breaker struct {
+	threshold float64
+	window    time.Duration
+	halfOpen  int
+
+	mtx      sync.Mutex
+	state    string
+	openedAt time.Time
+	admitted int // requests let through while half-open
+	passed   int // successful requests while half-open
+	buckets  [circuitBuckets]circuitBucket
+}
+
+// circuitBucket counts the requests in a slice of the window.
+type circuitBucket struct {
+	epoch         int64 // which slice of time the counts are for
+	total, errors int
+}
+
+func (b *breaker) validate() error {
+	switch {
+	case b.threshold <= 0 || b.threshold > 1:
+		return fmt.Errorf("circuit breaker threshold %v not between 0 and 1", b.threshold)
+	case b.window <= 0:
+		return fmt.Errorf("circuit breaker window must be positive")
+	case b.halfOpen < 1:
+		return fmt.Errorf("circuit breaker needs at least 1 half-open request")
+	}
+	return nil
+}
+
+// allow reports whether a request may go through. If the circuit changed
+// state it's returned as changed.
+func (b *breaker) allow(now time.Time) (ok bool, changed string) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.state == circuitOpen && now.Sub(b.openedAt) >= b.window {
+		b.state = circuitHalfOpen
+		b.admitted, b.passed = 0, 0
+		changed = circuitHalfOpen
+	}
+
+	switch b.state {
+	case circuitOpen:
+		return false, changed
+	case circuitHalfOpen:
+		if b.admitted >= b.halfOpen {
+			return false, changed
+		}
+		b.admitted++
+	}
+	return true, changed
+}
+
+// record records the status of a request that went through. If the circuit
+// changed state it's returned as changed, along with the error rate that
+// opened it.
+func (b *breaker) record(now time.Time, status int) (changed string, rate float64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	failed := status >= 500
+	switch b.state {
+	case circuitHalfOpen:
+		if failed {
+			b.open(now)
+			return circuitOpen, 0
+		}
+		b.passed++
+		if b.passed >= b.halfOpen {
+			b.state = circuitClosed
+			b.buckets = [circuitBuckets]circuitBucket{}
+			return circuitClosed, 0
+		}
+		return "", 0
+
+	case circuitClosed:
+		size := int64(b.window / circuitBuckets)
+		if size <= 0 {
+			size = 1
+		}
+		epoch := now.UnixNano() / size
+		bucket := &b.buckets[epoch%circuitBuckets]
+		if bucket.epoch != epoch {
+			bucket.epoch, bucket.total, bucket.errors = epoch, 0, 0
+		}
+		bucket.total++
+		if failed {
+			bucket.errors++
+		}
+
+		var total, errors int
+		for _, bk := range b.buckets {
+			if bk.epoch > epoch-circuitBuckets {
+				total += bk.total
+				errors += bk.errors
+			}
+		}
+		if total < minCircuitRequests {
+			return "", 0
+		}
+		if rate = float64(errors) / float64(total); rate >= b.threshold {
+			b.open(now)
+			return circuitOpen, rate
+		}
+	}
+	return "", 0
+}
+
+func (b *breaker) open(now time.Time) {
+	b.state = circuitOpen
+	b.openedAt = now
+}
+
+// logCircuit logs a change of the circuit breaker's state.
+func (l *Logger) logCircuit(state string, rate float64) {
+	attrs := []Attr{{"circuit_state", state}}
+	if rate > 0 {
+		attrs = append(attrs, Attr{"error_rate", fmt.Sprintf("%.2f", rate)})
+	}
+	l.logEvent(nil, "circuit_breaker", attrs...)
+}
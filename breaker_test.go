@@ -0,0 +1,66 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBreakerReleasesHalfOpenSlotOnPanic(t *testing.T) {
+	l, _ := newTestLogger(WithCircuitBreaker(0.5, time.Minute, 1))
+	halfOpen := func() {
+		l.breaker.mtx.Lock()
+		l.breaker.state = circuitOpen
+		l.breaker.openedAt = time.Now().Add(-time.Hour)
+		l.breaker.mtx.Unlock()
+	}
+
+	halfOpen()
+	func() {
+		defer func() { recover() }()
+		serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}, httptest.NewRequest("GET", "/", nil))
+	}()
+
+	if l.breaker.state != circuitOpen {
+		t.Fatalf("circuit is %s after a panicking half-open request, want %s", l.breaker.state, circuitOpen)
+	}
+
+	// Once the window has passed, the next request is let through
+	halfOpen()
+	w := serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", w.Code)
+	}
+	if l.breaker.state != circuitClosed {
+		t.Errorf("circuit is %s, want %s", l.breaker.state, circuitClosed)
+	}
+}
+
+func TestBreakerThreshold(t *testing.T) {
+	for _, tt := range []struct {
+		threshold float64
+		failures  int
+		open      bool
+	}{
+		{1, 9, false},
+		{1, 10, true},
+		{0.5, 4, false},
+		{0.5, 5, true},
+	} {
+		l, _ := newTestLogger(WithCircuitBreaker(tt.threshold, time.Minute, 1))
+		for i := 0; i < minCircuitRequests; i++ {
+			serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+				if i < tt.failures {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}, httptest.NewRequest("GET", "/", nil))
+		}
+		if open := l.breaker.state == circuitOpen; open != tt.open {
+			t.Errorf("threshold %v with %d of %d failing: open=%v, want %v",
+				tt.threshold, tt.failures, minCircuitRequests, open, tt.open)
+		}
+	}
+}
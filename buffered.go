@@ -0,0 +1,131 @@
+package babylogger
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// bufferSize is the size of the buffer WithBufferedOutput writes through.
+const bufferSize = 64 << 10
+
+// WithBufferedOutput buffers log lines in memory and writes them out every
+// flushInterval, or sooner when the buffer fills up, instead of issuing a
+// write for each line. At high request rates this saves a lot of system
+// calls, at the cost of lines showing up to flushInterval late.
+//
+// Buffered lines are written when the Logger is flushed or closed (see
+// Logger.Flush and Logger.Close), so close the Logger before the program
+// exits or the last lines may be lost.
+//
+// The output is the one set with WithOutput, or else the standard logger's
+// output at the time New is called. Lines for WithErrorSink's writer are
+// buffered the same way.
+func WithBufferedOutput(flushInterval time.Duration) Option {
+	return func(l *Logger) {
+		l.bufferInterval = flushInterval
+	}
+}
+
+// bufferedWriter buffers writes to the Logger's output.
+type bufferedWriter struct {
+	mtx sync.Mutex
+	out io.Writer
+	buf *bufio.Writer
+
+	closed   bool // once closed, lines are written right away
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// startBuffering sets up the Logger's output, and its error sink if it has
+// one, to be buffered.
+func (l *Logger) startBuffering() {
+	w := newBufferedWriter(l.writer(), l.bufferInterval)
+	l.out = w
+	l.buffered = w
+	if s := l.errSink; s != nil {
+		s.buffered = newBufferedWriter(s.w, l.bufferInterval)
+		s.setWriter(s.buffered)
+	}
+
+	// Pretty lines keep the prefix and flags of the logger they'd otherwise
+	// have been printed with
+	if l.logger != nil {
+		l.logger = log.New(w, l.logger.Prefix(), l.logger.Flags())
+	} else {
+		l.logger = log.New(w, log.Prefix(), log.Flags())
+	}
+}
+
+// newBufferedWriter returns a bufferedWriter for out that's flushed every
+// interval.
+func newBufferedWriter(out io.Writer, interval time.Duration) *bufferedWriter {
+	w := &bufferedWriter{
+		out:  out,
+		buf:  bufio.NewWriterSize(out, bufferSize),
+		done: make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.closed {
+		if err := w.buf.Flush(); err != nil {
+			return 0, err
+		}
+		return w.out.Write(p)
+	}
+	return w.buf.Write(p)
+}
+
+// IsJSONOutput implements JSONBackend for outputs that do.
+func (w *bufferedWriter) IsJSONOutput() bool {
+	jb, ok := w.out.(JSONBackend)
+	return ok && jb.IsJSONOutput()
+}
+
+// Flush writes out the buffered lines, and flushes the output if it buffers
+// too.
+func (w *bufferedWriter) Flush(ctx context.Context) error {
+	w.mtx.Lock()
+	err := w.buf.Flush()
+	w.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+	return flush(ctx, w.out)
+}
+
+// run flushes the buffer every interval until the writer is closed.
+func (w *bufferedWriter) run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			w.mtx.Lock()
+			w.buf.Flush()
+			w.mtx.Unlock()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// close writes out the buffered lines and stops the writer's goroutine.
+func (w *bufferedWriter) close() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.mtx.Lock()
+		w.closed = true
+		w.mtx.Unlock()
+		w.Flush(context.Background())
+	})
+}
@@ -0,0 +1,78 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBufferedOutputFlushOnClose(t *testing.T) {
+	out := new(syncBuffer)
+	l := New(WithOutput(out), WithNoColor(), WithBufferedOutput(time.Hour))
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/buffered", nil))
+
+	if got := out.String(); got != "" {
+		t.Fatalf("lines written before the flush interval:\n%s", got)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "<- GET /buffered") || !strings.Contains(got, "-> 200") {
+		t.Fatalf("lines not written on Close:\n%s", got)
+	}
+
+	// Once closed, lines are written right away
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/after", nil))
+	if got := out.String(); !strings.Contains(got, "/after") {
+		t.Errorf("line logged after Close wasn't written:\n%s", got)
+	}
+}
+
+func TestBufferedOutputInterval(t *testing.T) {
+	out := new(syncBuffer)
+	l := New(WithOutput(out), WithBufferedOutput(10*time.Millisecond))
+	defer l.Close()
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("lines not written after the flush interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func BenchmarkBufferedOutput(b *testing.B) {
+	for _, buffered := range []bool{false, true} {
+		name := "unbuffered"
+		if buffered {
+			name = "buffered"
+		}
+		b.Run(name, func(b *testing.B) {
+			f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer f.Close()
+
+			opts := []Option{WithOutput(f)}
+			if buffered {
+				opts = append(opts, WithBufferedOutput(time.Second))
+			}
+			l := New(opts...)
+			defer l.Close()
+
+			h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.ServeHTTP(w, r)
+			}
+		})
+	}
+}
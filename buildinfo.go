@@ -0,0 +1,45 @@
+package babylogger
+
+import "runtime/debug"
+
+// buildAttrs are the build details WithBuildInfo logs, read once at startup.
+var buildAttrs = readBuildInfo()
+
+// WithBuildInfo logs the program's module path, version and VCS revision
+// with every request, like:
+//
+//	module=github.com/myorg/myapp version=v1.2.3 revision=abc1234
+//
+// so it's clear from the logs alone which build served a request. The
+// details come from the build info embedded by the Go toolchain; ones it
+// doesn't have, like the revision of a binary built outside a repository,
+// are left out.
+func WithBuildInfo() Option {
+	return func(l *Logger) {
+		l.buildInfo = true
+	}
+}
+
+func readBuildInfo() []Attr {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	var attrs []Attr
+	if info.Main.Path != "" {
+		attrs = append(attrs, Attr{"module", info.Main.Path})
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		attrs = append(attrs, Attr{"version", v})
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && s.Value != "" {
+			rev := s.Value
+			if len(rev) > 7 {
+				rev = rev[:7]
+			}
+			attrs = append(attrs, Attr{"revision", rev})
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,28 @@
+package babylogger
+
+import "strings"
+
+// WithBypassMethods passes requests with the given methods, like OPTIONS and
+// HEAD, straight to the next handler, with the original ResponseWriter and
+// without any instrumentation: they aren't timed, logged, counted in Stats
+// or handed to EntryWriters, and options that act on requests, like rate
+// limiting, don't apply to them. Unlike filtering logs with WithLogDecider
+// or WithSampler, bypassing costs next to nothing.
+//
+// Methods are matched case-insensitively. WithBypassMethods can be given
+// more than once to bypass more methods.
+func WithBypassMethods(methods ...string) Option {
+	return func(l *Logger) {
+		if l.bypass == nil {
+			l.bypass = make(map[string]bool, len(methods))
+		}
+		for _, m := range methods {
+			l.bypass[strings.ToUpper(m)] = true
+		}
+	}
+}
+
+// bypassed reports whether requests with the given method are bypassed.
+func (l *Logger) bypassed(method string) bool {
+	return len(l.bypass) > 0 && l.bypass[strings.ToUpper(method)]
+}
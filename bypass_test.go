@@ -0,0 +1,50 @@
+package babylogger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBypassMethods(t *testing.T) {
+	l, out := newTestLogger(WithBypassMethods("options", "HEAD"))
+	var wrapped bool
+	h := func(w http.ResponseWriter, r *http.Request) {
+		_, wrapped = w.(*logWriter)
+	}
+
+	serveTest(l, h, httptest.NewRequest("OPTIONS", "/", nil))
+	if wrapped {
+		t.Error("bypassed request got a wrapped writer")
+	}
+	serveTest(l, h, httptest.NewRequest("HEAD", "/", nil))
+	if got := out.String(); got != "" {
+		t.Errorf("bypassed requests were logged:\n%s", got)
+	}
+
+	serveTest(l, h, httptest.NewRequest("GET", "/", nil))
+	if !wrapped {
+		t.Error("GET request didn't get a wrapped writer")
+	}
+}
+
+func BenchmarkBypassMethods(b *testing.B) {
+	l := New(WithOutput(io.Discard), WithBypassMethods(http.MethodOptions))
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, method := range []string{http.MethodOptions, http.MethodGet} {
+		name := "bypassed"
+		if method == http.MethodGet {
+			name = "instrumented"
+		}
+		b.Run(name, func(b *testing.B) {
+			r := httptest.NewRequest(method, "/", nil)
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.ServeHTTP(w, r)
+			}
+		})
+	}
+}
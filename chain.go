@@ -0,0 +1,145 @@
+package babylogger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Chain composes middleware into one, with the first wrapping the rest:
+// Chain(a, b, c)(h) is a(b(c(h))). The logging middleware is always placed
+// outermost, so request times include the rest of the chain; it's moved
+// there if it's among mw and added if it isn't:
+//
+//	http.ListenAndServe(":8000", babylogger.Chain(auth, gzip)(mux))
+func Chain(mw ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return std.Chain(mw...)
+}
+
+// SafeChain is like Chain, but recovers panics in each middleware. See
+// Logger.SafeChain.
+func SafeChain(mw ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return std.SafeChain(mw...)
+}
+
+// Chain composes middleware like the package-level Chain, with this
+// Logger's middleware outermost.
+func (l *Logger) Chain(mw ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	outer, rest := l.splitChain(mw)
+	return func(h http.Handler) http.Handler {
+		for i := len(rest) - 1; i >= 0; i-- {
+			h = rest[i](h)
+		}
+		return outer(h)
+	}
+}
+
+// SafeChain composes middleware like Chain, but with a panic recovery
+// boundary around each middleware. A panic in one of them is logged as a
+// middleware_panic event naming the middleware, like:
+//
+//	~> GET /users middleware_panic middleware=auth.Require.func1 panic="nil map"
+//
+// and the client gets a 500 Internal Server Error. Panics in the handler at
+// the end of the chain aren't recovered here, so WithPanicRecovery still
+// sees them. They go through each middleware as they were raised, so
+// recovery middleware in the chain gets the handler's panic value.
+func (l *Logger) SafeChain(mw ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	outer, rest := l.splitChain(mw)
+	return func(h http.Handler) http.Handler {
+		for i := len(rest) - 1; i >= 0; i-- {
+			h = l.recoverMiddleware(rest[i], h)
+		}
+		return outer(h)
+	}
+}
+
+var (
+	packageMiddleware = reflect.ValueOf(Middleware).Pointer()
+	methodMiddleware  = reflect.ValueOf(std.Middleware).Pointer()
+	methodThen        = reflect.ValueOf(std.Then).Pointer()
+)
+
+// splitChain separates the logging middleware, if it's in mw, from the rest.
+// If it isn't, l's middleware is used.
+func (l *Logger) splitChain(mw []func(http.Handler) http.Handler) (outer func(http.Handler) http.Handler, rest []func(http.Handler) http.Handler) {
+	outer = l.Middleware
+	for _, m := range mw {
+		if m == nil {
+			continue
+		}
+		switch reflect.ValueOf(m).Pointer() {
+		case packageMiddleware, methodMiddleware, methodThen:
+			outer = m
+		default:
+			rest = append(rest, m)
+		}
+	}
+	return outer, rest
+}
+
+// passedPanicKey is the context key of a request's *passedPanic.
+type passedPanicKey struct{}
+
+// passedPanic records that a panic came from further down the chain than a
+// middleware, so its recovery boundary doesn't blame the middleware for it.
+type passedPanic struct {
+	passed bool
+}
+
+// recoverMiddleware returns mw wrapping next, with a recovery boundary
+// around mw.
+func (l *Logger) recoverMiddleware(mw func(http.Handler) http.Handler, next http.Handler) http.Handler {
+	name := middlewareName(mw)
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if p, ok := r.Context().Value(passedPanicKey{}).(*passedPanic); ok {
+					p.passed = true
+				}
+				panic(v)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+	h := mw(inner)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := &passedPanic{}
+		r = r.WithContext(context.WithValue(r.Context(), passedPanicKey{}, p))
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if p.passed {
+				panic(v)
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			l.logEvent(&Entry{Method: r.Method, URI: l.loggedURI(r.RequestURI), ctx: r.Context()}, "middleware_panic",
+				Attr{"middleware", name}, Attr{"panic", fmt.Sprint(v)})
+			http.Error(w, http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError)
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// middlewareName returns the name of a middleware function, without its
+// package path.
+func middlewareName(mw func(http.Handler) http.Handler) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
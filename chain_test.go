@@ -0,0 +1,81 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recoverer is recovery middleware that records what it recovered.
+func recoverer(got *interface{}) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if v := recover(); v != nil {
+					*got = v
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func passThrough(next http.Handler) http.Handler { return next }
+
+func TestSafeChainRecoveryMiddleware(t *testing.T) {
+	l, out := newTestLogger()
+	var got interface{}
+	h := l.SafeChain(recoverer(&got), passThrough)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if got != "boom" {
+		t.Errorf("recovery middleware got %#v, want the handler's panic value", got)
+	}
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want the recovery middleware's", w.Code)
+	}
+	if s := out.String(); strings.Contains(s, "middleware_panic") {
+		t.Errorf("handler panic blamed on middleware:\n%s", s)
+	}
+}
+
+func TestSafeChainPassesHandlerPanics(t *testing.T) {
+	l, out := newTestLogger(WithPanicRecovery())
+	h := l.SafeChain(passThrough, passThrough)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	s := out.String()
+	if strings.Contains(s, "middleware_panic") || !strings.Contains(s, "panic=boom") {
+		t.Errorf("handler panic not passed through:\n%s", s)
+	}
+}
+
+func TestSafeChainMiddlewarePanic(t *testing.T) {
+	l, out := newTestLogger()
+	bad := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("nil map")
+		})
+	}
+	h := l.SafeChain(passThrough, bad)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if s := out.String(); !strings.Contains(s, "middleware_panic") || !strings.Contains(s, `panic="nil map"`) {
+		t.Errorf("middleware panic not logged:\n%s", s)
+	}
+}
@@ -0,0 +1,59 @@
+// Package chi logs the routing details of the chi router. It lives in its
+// own package so chi is only pulled in by programs that use it.
+//
+// The logger has to be added as router middleware, so it runs inside the
+// router and sees its routing context. With this package imported as
+// babychi:
+//
+//	l := babylogger.New(babychi.WithChiContext())
+//
+//	r := chi.NewRouter()
+//	r.Use(l.Middleware)
+//	r.Get("/users/{id}", getUser)
+//
+// Requests are then logged like:
+//
+//	-> 200 OK 512B 1.2ms route_pattern=/users/{id} route_params=id:42
+package chi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/meowgorithm/babylogger"
+)
+
+// WithChiContext logs the route pattern chi matched as route_pattern, and
+// the URL parameters it extracted as route_params, like id:42, separated by
+// commas when there are several. Requests chi didn't route, like those to
+// the logger outside of a chi router, don't get either.
+func WithChiContext() babylogger.Option {
+	return babylogger.WithResponseAttrs(routeAttrs)
+}
+
+// routeAttrs returns the attributes for a request's chi routing context.
+func routeAttrs(r *http.Request) []babylogger.Attr {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return nil
+	}
+
+	var attrs []babylogger.Attr
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		attrs = append(attrs, babylogger.Attr{Key: "route_pattern", Value: pattern})
+	}
+	if keys := rctx.URLParams.Keys; len(keys) > 0 {
+		params := make([]string, 0, len(keys))
+		for i, k := range keys {
+			if k == "" || i >= len(rctx.URLParams.Values) {
+				continue
+			}
+			params = append(params, k+":"+rctx.URLParams.Values[i])
+		}
+		if len(params) > 0 {
+			attrs = append(attrs, babylogger.Attr{Key: "route_params", Value: strings.Join(params, ",")})
+		}
+	}
+	return attrs
+}
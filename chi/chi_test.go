@@ -0,0 +1,58 @@
+package chi
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/meowgorithm/babylogger"
+)
+
+func TestWithChiContext(t *testing.T) {
+	out := new(bytes.Buffer)
+	l := babylogger.New(babylogger.WithOutput(out), babylogger.WithNoColor(), WithChiContext())
+
+	r := chi.NewRouter()
+	r.Use(l.Middleware)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	r.Get("/orgs/{org}/repos/{repo}", func(w http.ResponseWriter, r *http.Request) {})
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		path    string
+		want    []string
+		notWant []string
+	}{
+		{"/users/42", []string{"route_pattern=/users/{id}", "route_params=id:42"}, nil},
+		{"/orgs/charm/repos/glow", []string{"route_pattern=/orgs/{org}/repos/{repo}", "route_params=org:charm,repo:glow"}, nil},
+		{"/health", []string{"route_pattern=/health"}, []string{"route_params"}},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+		got := out.String()
+		for _, s := range tt.want {
+			if !strings.Contains(got, s) {
+				t.Errorf("%s: %q missing from:\n%s", tt.path, s, got)
+			}
+		}
+		for _, s := range tt.notWant {
+			if strings.Contains(got, s) {
+				t.Errorf("%s: unexpected %q in:\n%s", tt.path, s, got)
+			}
+		}
+	}
+}
+
+func TestWithChiContextOutsideRouter(t *testing.T) {
+	out := new(bytes.Buffer)
+	l := babylogger.New(babylogger.WithOutput(out), babylogger.WithNoColor(), WithChiContext())
+	l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	if got := out.String(); strings.Contains(got, "route_") {
+		t.Errorf("routing attrs logged outside a chi router:\n%s", got)
+	}
+}
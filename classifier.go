@@ -0,0 +1,68 @@
+package babylogger
+
+import "github.com/charmbracelet/lipgloss"
+
+// StatusClass is a group of response statuses that are rendered and leveled
+// alike.
+type StatusClass struct {
+	// Name identifies the class. The classes the default classifier uses are
+	// rendered with the Theme's status styles; others with Style.
+	Name string
+
+	// Level is the severity of responses in the class: info, warn or error.
+	Level string
+
+	// Style renders statuses of custom classes in the pretty format.
+	Style lipgloss.Style
+}
+
+// The classes of the default classifier.
+var (
+	ClassSuccess     = StatusClass{Name: "success", Level: "info"}       // 1xx and 2xx
+	ClassRedirect    = StatusClass{Name: "redirect", Level: "info"}      // 3xx
+	ClassClientError = StatusClass{Name: "client_error", Level: "warn"}  // 4xx
+	ClassServerError = StatusClass{Name: "server_error", Level: "error"} // 5xx
+)
+
+// DefaultStatusClassifier puts statuses in classes by their first digit.
+func DefaultStatusClassifier(code int) StatusClass {
+	switch {
+	case code < 300:
+		return ClassSuccess
+	case code < 400:
+		return ClassRedirect
+	case code < 500:
+		return ClassClientError
+	}
+	return ClassServerError
+}
+
+// WithStatusClassifier sets how response statuses are grouped. A status's
+// class decides its color in the pretty format and its severity in the
+// structured ones. For example, to tell cached responses apart from other
+// redirects:
+//
+//	cached := babylogger.StatusClass{
+//		Name:  "cached",
+//		Level: "info",
+//		Style: lipgloss.NewStyle().Foreground(lipgloss.Color("37")),
+//	}
+//	babylogger.WithStatusClassifier(func(code int) babylogger.StatusClass {
+//		if code == http.StatusNotModified {
+//			return cached
+//		}
+//		return babylogger.DefaultStatusClassifier(code)
+//	})
+//
+// Rate limited responses are highlighted regardless of their class; see
+// WithNoRateLimitHighlight.
+func WithStatusClassifier(fn func(code int) StatusClass) Option {
+	return func(l *Logger) {
+		l.classify = fn
+	}
+}
+
+// level returns the severity of a response with the given status code.
+func (l *Logger) level(code int) string {
+	return l.classify(code).Level
+}
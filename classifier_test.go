@@ -0,0 +1,53 @@
+package babylogger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestStatusClassifierCached(t *testing.T) {
+	r := colorRenderer()
+	cached := StatusClass{
+		Name:  "cached",
+		Level: "warn",
+		Style: r.NewStyle().Foreground(lipgloss.Color("37")),
+	}
+	theme := DefaultTheme().renderer(r)
+	l := New(WithTheme(theme), WithStatusClassifier(func(code int) StatusClass {
+		if code == http.StatusNotModified {
+			return cached
+		}
+		return DefaultStatusClassifier(code)
+	}))
+
+	if got, want := l.statusStyle(304).Render("304"), cached.Style.Render("304"); got != want {
+		t.Errorf("304 rendered as %q, want %q", got, want)
+	}
+	if got, want := l.statusStyle(301).Render("301"), theme.Status3xx.Render("301"); got != want {
+		t.Errorf("301 rendered as %q, want %q", got, want)
+	}
+	if got := l.level(304); got != "warn" {
+		t.Errorf("304 has level %q, want warn", got)
+	}
+	if got := l.level(301); got != "info" {
+		t.Errorf("301 has level %q, want info", got)
+	}
+}
+
+func TestDefaultStatusClassifier(t *testing.T) {
+	tests := map[int]StatusClass{
+		100: ClassSuccess,
+		204: ClassSuccess,
+		304: ClassRedirect,
+		404: ClassClientError,
+		499: ClassClientError,
+		503: ClassServerError,
+	}
+	for code, want := range tests {
+		if got := DefaultStatusClassifier(code); got.Name != want.Name {
+			t.Errorf("%d classified as %s, want %s", code, got.Name, want.Name)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Package clickhouse ships Babylogger entries to ClickHouse for access log
+// analysis. Entries are batched in memory and inserted through ClickHouse's
+// HTTP interface using the JSONEachRow format, so there are no dependencies
+// beyond the standard library.
+//
+// Example:
+//
+//	w := clickhouse.New(clickhouse.ClickHouseConfig{
+//		URL: "http://localhost:8123",
+//	})
+//	defer w.Close()
+//
+//	// Create the table once, e.g. in a migration
+//	fmt.Println(w.CreateTableSQL())
+//
+//	l := babylogger.New(babylogger.WithEntryWriter(w))
+//	http.ListenAndServe(":8000", l.Middleware(mux))
+package clickhouse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/meowgorithm/babylogger"
+)
+
+// Defaults.
+const (
+	DefaultTable         = "access_logs"
+	DefaultBatchSize     = 1000
+	DefaultFlushInterval = 5 * time.Second
+)
+
+// ClickHouseConfig configures a ClickHouseWriter.
+type ClickHouseConfig struct {
+	// URL is the address of ClickHouse's HTTP interface, for example
+	// http://localhost:8123.
+	URL string
+
+	// Database and Table are where rows are inserted. Table defaults to
+	// access_logs; Database defaults to the server's default database.
+	Database string
+	Table    string
+
+	// Username and Password are sent as basic auth when set.
+	Username string
+	Password string
+
+	// Rows are inserted once BatchSize rows have accumulated or every
+	// FlushInterval, whichever comes first.
+	BatchSize     int
+	FlushInterval time.Duration
+
+	// Client is the HTTP client used for inserts. Defaults to a client with
+	// a 30 second timeout.
+	Client *http.Client
+
+	// OnError is called when an insert fails. The rows in that insert are
+	// dropped. Defaults to logging the error with the standard logger.
+	OnError func(error)
+}
+
+// ClickHouseWriter is a babylogger.EntryWriter that batches entries and
+// inserts them into ClickHouse.
+type ClickHouseWriter struct {
+	cfg ClickHouseConfig
+
+	mtx    sync.Mutex
+	rows   [][]byte
+	closed bool
+
+	flush chan struct{}
+	done  chan struct{}
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+// New returns a ClickHouseWriter and starts its background flushing. Call
+// Close to flush remaining rows and stop it.
+func New(cfg ClickHouseConfig) *ClickHouseWriter {
+	if cfg.Table == "" {
+		cfg.Table = DefaultTable
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) {
+			log.Printf("babylogger/clickhouse: %v", err)
+		}
+	}
+
+	w := &ClickHouseWriter{
+		cfg:   cfg,
+		flush: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.loop()
+	return w
+}
+
+// row is the JSONEachRow representation of an entry. Keep it in sync with
+// CreateTableSQL.
+type row struct {
+	Timestamp  string            `json:"timestamp"`
+	Method     string            `json:"method"`
+	URI        string            `json:"uri"`
+	Path       string            `json:"path"`
+	Proto      string            `json:"proto"`
+	RemoteAddr string            `json:"remote_addr"`
+	Status     int               `json:"status"`
+	Bytes      int               `json:"bytes"`
+	DurationNS int64             `json:"duration_ns"`
+	Attrs      map[string]string `json:"attrs"`
+}
+
+// WriteEntry queues an entry for insertion. It never blocks on the network.
+// Once the writer is closed it returns an error instead.
+func (w *ClickHouseWriter) WriteEntry(e babylogger.Entry) error {
+	r := row{
+		Timestamp:  e.Time.UTC().Format("2006-01-02 15:04:05.000"),
+		Method:     e.Method,
+		URI:        e.URI,
+		Path:       e.Path,
+		Proto:      e.Proto,
+		RemoteAddr: e.RemoteAddr,
+		Status:     e.Status,
+		Bytes:      e.Bytes,
+		DurationNS: int64(e.Duration),
+		Attrs:      make(map[string]string, len(e.Attrs)),
+	}
+	for _, a := range e.Attrs {
+		r.Attrs[a.Key] = fmt.Sprint(a.Value)
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	w.mtx.Lock()
+	if w.closed {
+		w.mtx.Unlock()
+		return errors.New("babylogger/clickhouse: write to closed ClickHouseWriter")
+	}
+	w.rows = append(w.rows, b)
+	full := len(w.rows) >= w.cfg.BatchSize
+	w.mtx.Unlock()
+
+	if full {
+		select {
+		case w.flush <- struct{}{}:
+		default: // a flush is already pending
+		}
+	}
+	return nil
+}
+
+// Flush inserts any queued rows immediately.
+func (w *ClickHouseWriter) Flush() error {
+	w.mtx.Lock()
+	rows := w.rows
+	w.rows = nil
+	w.mtx.Unlock()
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return w.insert(rows)
+}
+
+// Close flushes any queued rows and stops the background flushing. Entries
+// written after Close are rejected.
+func (w *ClickHouseWriter) Close() error {
+	w.once.Do(func() {
+		w.mtx.Lock()
+		w.closed = true
+		w.mtx.Unlock()
+		close(w.done)
+	})
+	w.wg.Wait()
+	return w.Flush()
+}
+
+func (w *ClickHouseWriter) loop() {
+	defer w.wg.Done()
+
+	t := time.NewTicker(w.cfg.FlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-t.C:
+		case <-w.flush:
+		}
+		if err := w.Flush(); err != nil {
+			w.cfg.OnError(err)
+		}
+	}
+}
+
+func (w *ClickHouseWriter) insert(rows [][]byte) error {
+	var body bytes.Buffer
+	for _, r := range rows {
+		body.Write(r)
+		body.WriteByte('\n')
+	}
+
+	q := url.Values{}
+	q.Set("query", fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", w.table()))
+	if w.cfg.Database != "" {
+		q.Set("database", w.cfg.Database)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.cfg.URL+"/?"+q.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if w.cfg.Username != "" {
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+
+	res, err := w.cfg.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("inserting %d rows: %v", len(rows), err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("inserting %d rows: %s: %s", len(rows), res.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func (w *ClickHouseWriter) table() string {
+	if w.cfg.Database != "" {
+		return w.cfg.Database + "." + w.cfg.Table
+	}
+	return w.cfg.Table
+}
+
+// CreateTableSQL returns a CREATE TABLE statement for a table matching the
+// rows this ClickHouseWriter inserts.
+func (w *ClickHouseWriter) CreateTableSQL() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    timestamp   DateTime64(3, 'UTC'),
+    method      LowCardinality(String),
+    uri         String,
+    path        String,
+    proto       LowCardinality(String),
+    remote_addr String,
+    status      UInt16,
+    bytes       UInt64,
+    duration_ns UInt64,
+    attrs       Map(String, String)
+) ENGINE = MergeTree
+ORDER BY timestamp`, w.table())
+}
@@ -0,0 +1,80 @@
+package clickhouse
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meowgorithm/babylogger"
+)
+
+// server is a fake ClickHouse HTTP interface that records inserted rows.
+type server struct {
+	mtx     sync.Mutex
+	queries []string
+	rows    []map[string]interface{}
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.queries = append(s.queries, r.URL.Query().Get("query"))
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.rows = append(s.rows, row)
+	}
+}
+
+func TestClickHouseWriter(t *testing.T) {
+	s := &server{}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	w := New(ClickHouseConfig{URL: ts.URL, FlushInterval: time.Hour})
+	e := babylogger.Entry{
+		Time:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Method:   "GET",
+		URI:      "/users/42",
+		Status:   200,
+		Duration: time.Millisecond,
+		Attrs:    []babylogger.Attr{{Key: "request_id", Value: "abc"}},
+	}
+	for i := 0; i < 2; i++ {
+		if err := w.WriteEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.queries) != 1 || s.queries[0] != "INSERT INTO access_logs FORMAT JSONEachRow" {
+		t.Errorf("got queries %q", s.queries)
+	}
+	if len(s.rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(s.rows))
+	}
+	if got := s.rows[0]["timestamp"]; got != "2026-01-02 03:04:05.000" {
+		t.Errorf("got timestamp %v", got)
+	}
+	if got := s.rows[0]["attrs"].(map[string]interface{})["request_id"]; got != "abc" {
+		t.Errorf("got request_id %v", got)
+	}
+
+	if err := w.WriteEntry(e); err == nil {
+		t.Error("no error writing to a closed writer")
+	}
+	if len(s.rows) != 2 {
+		t.Errorf("entry written after Close was inserted")
+	}
+}
@@ -0,0 +1,53 @@
+package babylogger
+
+import (
+	"net/http"
+	"time"
+)
+
+// skewTolerance is the skew below which a request's Date header is taken to
+// agree with the server's clock. Date only has a precision of a second.
+const skewTolerance = time.Second
+
+// WithClockSkewDetection compares the Date header of requests that have one
+// to the server's clock and logs the difference when it's over a second,
+// like clock_skew=+1.2s for a client whose clock is ahead. When the skew is
+// over maxSkew, clock_skew_alert=true is logged too. Skewed clocks are a
+// common cause of tokens being rejected as expired or not yet valid.
+func WithClockSkewDetection(maxSkew time.Duration) Option {
+	return func(l *Logger) {
+		l.maxClockSkew = maxSkew
+		l.clockSkew = true
+	}
+}
+
+// addClockSkew logs the skew between the request's Date header, if any, and
+// the time it was received.
+func (l *Logger) addClockSkew(r *http.Request, e *Entry) {
+	h := r.Header.Get("Date")
+	if h == "" {
+		return
+	}
+	date, err := http.ParseTime(h)
+	if err != nil {
+		return
+	}
+
+	skew := date.Sub(e.Time).Round(100 * time.Millisecond)
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs <= skewTolerance {
+		return
+	}
+
+	s := skew.String()
+	if skew > 0 {
+		s = "+" + s
+	}
+	e.add("clock_skew", s)
+	if l.maxClockSkew > 0 && abs > l.maxClockSkew {
+		e.add("clock_skew_alert", true)
+	}
+}
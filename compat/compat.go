@@ -0,0 +1,203 @@
+// Package compat provides drop-in replacements for other logging middleware,
+// to make switching to Babylogger a small change.
+//
+// For example, code using gorilla/handlers:
+//
+//	http.ListenAndServe(":8080", handlers.LoggingHandler(os.Stdout, mux))
+//
+// can switch with a one-symbol swap and keep the same output:
+//
+//	http.ListenAndServe(":8080", compat.CompatGorilla(os.Stdout, mux))
+//
+// From there, Babylogger's options can be adopted progressively, keeping the
+// old log format around with CLFWriter for as long as something depends on
+// it:
+//
+//	l := babylogger.New(
+//		babylogger.WithEntryWriter(compat.CLFWriter(os.Stdout)),
+//		babylogger.WithPanicRecovery(),
+//	)
+package compat
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/meowgorithm/babylogger"
+)
+
+// CompatGorilla is a replacement for gorilla/handlers' LoggingHandler. It
+// logs requests to out in the Apache Common Log Format, like:
+//
+//	127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326
+//
+// The output is the same as LoggingHandler's. Nothing is written to
+// Babylogger's usual output.
+func CompatGorilla(out io.Writer, h http.Handler) http.Handler {
+	l := babylogger.New(
+		babylogger.WithLogDecider(func(babylogger.Entry) bool { return false }),
+		babylogger.WithResponseAttrs(gorillaFields),
+		babylogger.WithEntryWriter(CLFWriter(out)),
+	)
+	return l.Middleware(h)
+}
+
+// gorillaKey is the key of the attribute CompatGorilla adds to entries, with
+// the request's fields as gorilla/handlers logs them.
+const gorillaKey = "compat_gorilla"
+
+// gorillaRequest holds the fields of a request that gorilla/handlers logs
+// differently from Babylogger's Entry.
+type gorillaRequest struct {
+	host, user, uri string
+}
+
+// gorillaFields returns the attribute with the fields of r that
+// gorilla/handlers' buildCommonLogLine logs.
+func gorillaFields(r *http.Request) []babylogger.Attr {
+	f := gorillaRequest{host: r.RemoteAddr, user: "-", uri: r.RequestURI}
+	if r.URL.User != nil {
+		if name := r.URL.User.Username(); name != "" {
+			f.user = name
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		f.host = host
+	}
+	// CONNECT requests over HTTP/2 have their target in the authority
+	if r.ProtoMajor == 2 && r.Method == http.MethodConnect {
+		f.uri = r.Host
+	}
+	if f.uri == "" {
+		f.uri = r.URL.RequestURI()
+	}
+	return []babylogger.Attr{{Key: gorillaKey, Value: f}}
+}
+
+// CLFWriter returns an EntryWriter that writes entries to out in the Apache
+// Common Log Format, formatted like gorilla/handlers does. Its lines only
+// differ from gorilla's in the fields Babylogger's Entry doesn't have: the
+// user is the remote_user attribute set by babylogger.WithRemoteUser rather
+// than the request URL's, and CONNECT requests over HTTP/2 are logged with
+// their URI rather than their Host. CompatGorilla's lines don't differ at
+// all.
+func CLFWriter(out io.Writer) babylogger.EntryWriter {
+	return &clfWriter{out: out}
+}
+
+type clfWriter struct {
+	mtx sync.Mutex
+	out io.Writer
+}
+
+func (w *clfWriter) WriteEntry(e babylogger.Entry) error {
+	// Babylogger keeps the brackets around IPv6 addresses, gorilla doesn't
+	host := strings.TrimSuffix(strings.TrimPrefix(e.RemoteAddr, "["), "]")
+	uri := e.URI
+	// Without CompatGorilla the user is only known with
+	// babylogger.WithRemoteUser
+	user := "-"
+	for _, a := range e.Attrs {
+		if a.Key == "remote_user" {
+			if s, ok := a.Value.(string); ok && s != "" {
+				user = s
+			}
+		}
+	}
+	for _, a := range e.Attrs {
+		if f, ok := a.Value.(gorillaRequest); ok && a.Key == gorillaKey {
+			host, user, uri = f.host, f.user, f.uri
+		}
+	}
+
+	buf := make([]byte, 0, 3*(len(host)+len(user)+len(e.Method)+len(uri)+len(e.Proto)+50)/2)
+
+	buf = append(buf, host...)
+	buf = append(buf, " - "...)
+	buf = append(buf, user...)
+	buf = append(buf, " ["...)
+	buf = append(buf, e.Time.Format("02/Jan/2006:15:04:05 -0700")...)
+	buf = append(buf, `] "`...)
+	buf = append(buf, e.Method...)
+	buf = append(buf, ' ')
+	buf = appendQuoted(buf, uri)
+	buf = append(buf, ' ')
+	buf = append(buf, e.Proto...)
+	buf = append(buf, `" `...)
+	buf = strconv.AppendInt(buf, int64(e.Status), 10)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(e.Bytes), 10)
+	buf = append(buf, '\n')
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	_, err := w.out.Write(buf)
+	return err
+}
+
+const lowerhex = "0123456789abcdef"
+
+// appendQuoted appends s to buf, escaping quotes, backslashes and
+// non-printable characters the way gorilla/handlers does.
+func appendQuoted(buf []byte, s string) []byte {
+	var runeTmp [utf8.UTFMax]byte
+	for width := 0; len(s) > 0; s = s[width:] {
+		r := rune(s[0])
+		width = 1
+		if r >= utf8.RuneSelf {
+			r, width = utf8.DecodeRuneInString(s)
+		}
+		if width == 1 && r == utf8.RuneError {
+			buf = append(buf, `\x`...)
+			buf = append(buf, lowerhex[s[0]>>4], lowerhex[s[0]&0xF])
+			continue
+		}
+		if r == '"' || r == '\\' {
+			buf = append(buf, '\\', byte(r))
+			continue
+		}
+		if strconv.IsPrint(r) {
+			n := utf8.EncodeRune(runeTmp[:], r)
+			buf = append(buf, runeTmp[:n]...)
+			continue
+		}
+		switch r {
+		case '\a':
+			buf = append(buf, `\a`...)
+		case '\b':
+			buf = append(buf, `\b`...)
+		case '\f':
+			buf = append(buf, `\f`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		case '\t':
+			buf = append(buf, `\t`...)
+		case '\v':
+			buf = append(buf, `\v`...)
+		default:
+			switch {
+			case r < ' ':
+				buf = append(buf, `\x`...)
+				buf = append(buf, lowerhex[s[0]>>4], lowerhex[s[0]&0xF])
+			case r < 0x10000:
+				buf = append(buf, `\u`...)
+				for s := 12; s >= 0; s -= 4 {
+					buf = append(buf, lowerhex[r>>uint(s)&0xF])
+				}
+			default:
+				buf = append(buf, `\U`...)
+				for s := 28; s >= 0; s -= 4 {
+					buf = append(buf, lowerhex[r>>uint(s)&0xF])
+				}
+			}
+		}
+	}
+	return buf
+}
@@ -0,0 +1,81 @@
+package compat
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/gorilla/handlers"
+)
+
+var timestamp = regexp.MustCompile(`\[[^]]+\]`)
+
+func TestCompatGorillaMatchesLoggingHandler(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello"))
+	})
+
+	tests := []struct {
+		name string
+		req  func() *http.Request
+	}{
+		{"plain", func() *http.Request {
+			return httptest.NewRequest("GET", "/hello?a=1", nil)
+		}},
+		{"not found", func() *http.Request {
+			return httptest.NewRequest("POST", "/missing", nil)
+		}},
+		{"url user", func() *http.Request {
+			r := httptest.NewRequest("GET", "/hello", nil)
+			r.URL.User = url.User("gopher")
+			return r
+		}},
+		{"ipv6", func() *http.Request {
+			r := httptest.NewRequest("GET", "/hello", nil)
+			r.RemoteAddr = "[::1]:1234"
+			return r
+		}},
+		{"no port", func() *http.Request {
+			r := httptest.NewRequest("GET", "/hello", nil)
+			r.RemoteAddr = "::1"
+			return r
+		}},
+		{"quoted", func() *http.Request {
+			r := httptest.NewRequest("GET", "/hello", nil)
+			r.RequestURI = "/\"quoted\"\\\x01é\xff"
+			return r
+		}},
+		{"no request uri", func() *http.Request {
+			r := httptest.NewRequest("GET", "/hello?a=1", nil)
+			r.RequestURI = ""
+			return r
+		}},
+		{"connect http/2", func() *http.Request {
+			r := httptest.NewRequest("CONNECT", "/", nil)
+			r.Proto, r.ProtoMajor, r.ProtoMinor = "HTTP/2.0", 2, 0
+			r.Host = "example.com:443"
+			r.RequestURI = ""
+			return r
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want, got bytes.Buffer
+			handlers.LoggingHandler(&want, h).ServeHTTP(httptest.NewRecorder(), tt.req())
+			CompatGorilla(&got, h).ServeHTTP(httptest.NewRecorder(), tt.req())
+
+			w := timestamp.ReplaceAllString(want.String(), "[ts]")
+			g := timestamp.ReplaceAllString(got.String(), "[ts]")
+			if g != w {
+				t.Errorf("got  %q\nwant %q", g, w)
+			}
+		})
+	}
+}
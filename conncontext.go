@@ -0,0 +1,48 @@
+package babylogger
+
+import (
+	"context"
+	"net"
+)
+
+type connKey struct{}
+
+// connAddrs are the addresses of a connection, and its number, stored in
+// its context by ConnContextMiddleware.
+type connAddrs struct {
+	local, remote net.Addr
+	id            uint64
+}
+
+// ConnContextMiddleware is an http.Server.ConnContext hook that stores the
+// connection's local and remote addresses, and a number identifying it, in
+// its context, and so in the context of every request it carries:
+//
+//	srv := &http.Server{
+//		Handler:     l.Middleware(mux),
+//		ConnContext: babylogger.ConnContextMiddleware,
+//	}
+//
+// Babylogger then takes the client address from the connection rather than
+// from the request's RemoteAddr, and the local address, with WithLocalAddr,
+// from the connection rather than from http.LocalAddrContextKey. This makes
+// a difference for listeners whose connections report addresses the server
+// doesn't, like listeners for the PROXY protocol, and when one handler
+// serves several servers or listeners. With WithConnectionID, the number is
+// logged to tell connections apart.
+//
+// To use it along with another ConnContext hook, call it from that hook.
+func ConnContextMiddleware(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, connAddrs{
+		local:  c.LocalAddr(),
+		remote: c.RemoteAddr(),
+		id:     nextConnID(),
+	})
+}
+
+// connAddrsFromContext returns the connection addresses stored in ctx by
+// ConnContextMiddleware, if any.
+func connAddrsFromContext(ctx context.Context) (connAddrs, bool) {
+	a, ok := ctx.Value(connKey{}).(connAddrs)
+	return a, ok
+}
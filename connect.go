@@ -0,0 +1,90 @@
+package babylogger
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// connectDialTimeout bounds how long connecting to a CONNECT target may take.
+const connectDialTimeout = 10 * time.Second
+
+// WithCONNECTLogging serves CONNECT requests, the ones HTTPS proxies receive,
+// by opening a TCP tunnel to the requested target, and logs the tunnel. The
+// request line includes the target, like connect_target=example.com:443, and
+// the response line is logged once the tunnel closes with what went through
+// it:
+//
+//	tunnel_bytes_in=8192 tunnel_bytes_out=1024 tunnel_duration=1m23s
+//
+// Bytes in are the ones received from the client, and bytes out the ones sent
+// back to it. CONNECT requests don't reach the handler. If the target can't be
+// reached the client gets a 502 Bad Gateway, logged with connect_error.
+//
+// This turns the server into an open proxy for anyone who can reach it, so
+// only enable it behind access control.
+func WithCONNECTLogging() Option {
+	return func(l *Logger) {
+		l.connectLogging = true
+	}
+}
+
+// serveConnect tunnels a CONNECT request to its target.
+func (l *Logger) serveConnect(w *logWriter, r *http.Request, e *Entry) {
+	target, err := net.DialTimeout("tcp", r.Host, connectDialTimeout)
+	if err != nil {
+		e.add("connect_error", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	defer target.Close()
+
+	client, rw, err := w.Hijack()
+	if err != nil {
+		e.add("connect_error", err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer client.Close()
+
+	start := time.Now()
+	if _, err := io.WriteString(client, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		e.add("connect_error", err.Error())
+		return
+	}
+
+	var in, out int64
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		// Read through rw, which may have buffered some of the client's data
+		n, _ := io.Copy(target, rw)
+		atomic.StoreInt64(&in, n)
+		closeWrite(target)
+	}()
+	go func() {
+		defer wg.Done()
+		n, _ := io.Copy(client, target)
+		atomic.StoreInt64(&out, n)
+		closeWrite(client)
+	}()
+	wg.Wait()
+
+	e.add("tunnel_bytes_in", in)
+	e.add("tunnel_bytes_out", out)
+	e.add("tunnel_duration", time.Since(start).Round(time.Millisecond))
+}
+
+// closeWrite shuts down the writing side of a connection, so the other end
+// sees EOF, or closes the connection entirely if that isn't supported.
+func closeWrite(c net.Conn) {
+	if cw, ok := c.(interface{ CloseWrite() error }); ok {
+		cw.CloseWrite()
+		return
+	}
+	c.Close()
+}
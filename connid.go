@@ -0,0 +1,51 @@
+package babylogger
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// connCounter numbers the connections seen by ConnContextMiddleware.
+var connCounter uint64
+
+// WithConnectionID logs an identifier for the connection each request came
+// in on, as conn_id. Under HTTP/2 many requests share a single connection,
+// and the identifier is what groups their streams together in the logs.
+//
+// net/http doesn't number connections, so for stable identifiers the server
+// has to use ConnContextMiddleware, which numbers each connection as it's
+// accepted:
+//
+//	srv := &http.Server{
+//		Handler:     l.Middleware(mux),
+//		ConnContext: babylogger.ConnContextMiddleware,
+//	}
+//
+// The numbers count up from 1 and are unique within the process. Without
+// the hook the identifier is made up from the connection's local and remote
+// addresses instead, like 127.0.0.1:8080-192.0.2.1:51234, which is stable
+// for as long as the connection lasts but may come up again once the client
+// reuses the port.
+func WithConnectionID() Option {
+	return func(l *Logger) {
+		l.connID = true
+	}
+}
+
+// addConnID adds the connection identifier to an entry.
+func addConnID(r *http.Request, e *Entry) {
+	if conn, ok := connAddrsFromContext(r.Context()); ok && conn.id != 0 {
+		e.add("conn_id", strconv.FormatUint(conn.id, 10))
+		return
+	}
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		e.add("conn_id", addr.String()+"-"+r.RemoteAddr)
+	}
+}
+
+// nextConnID returns the identifier for a new connection.
+func nextConnID() uint64 {
+	return atomic.AddUint64(&connCounter, 1)
+}
@@ -0,0 +1,30 @@
+package babylogger
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// WithContentLengthFallback logs the response's Content-Length header as its
+// size when no bytes were counted, along with bytes_from_header=true. This
+// covers handlers that write the body around the middleware's writer, which
+// would otherwise be logged as 0B. Responses to HEAD requests, and 204 and
+// 304 responses, never have a body, so their size stays 0.
+func WithContentLengthFallback() Option {
+	return func(l *Logger) {
+		l.lengthFallback = true
+	}
+}
+
+// contentLength returns the size the response's Content-Length header
+// declares, if it should be logged in place of the counted size.
+func contentLength(method string, w *logWriter) (int, bool) {
+	if method == http.MethodHead || w.code == http.StatusNoContent || w.code == http.StatusNotModified {
+		return 0, false
+	}
+	n, err := strconv.Atoi(w.Header().Get("Content-Length"))
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
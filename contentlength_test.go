@@ -0,0 +1,64 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentLengthFallbackServeContent(t *testing.T) {
+	const body = "hello, world"
+
+	// ServeContent writes straight to the writer the middleware wraps, so
+	// none of the bytes go through its counter
+	serveAround := func(w http.ResponseWriter, r *http.Request) {
+		raw := w.(interface{ Unwrap() http.ResponseWriter }).Unwrap()
+		http.ServeContent(raw, r, "hello.txt", time.Time{}, strings.NewReader(body))
+	}
+
+	tests := []struct {
+		method   string
+		fallback bool
+		bytes    int
+	}{
+		{"GET", true, len(body)},
+		{"GET", false, 0},
+		{"HEAD", true, 0},
+	}
+	for _, tt := range tests {
+		var entry Entry
+		opts := []Option{WithEntryWriter(entryWriterFunc(func(e Entry) error {
+			entry = e
+			return nil
+		}))}
+		if tt.fallback {
+			opts = append(opts, WithContentLengthFallback())
+		}
+		l, _ := newTestLogger(opts...)
+		serveTest(l, serveAround, httptest.NewRequest(tt.method, "/", nil))
+
+		if entry.Bytes != tt.bytes {
+			t.Errorf("%s with fallback %v: logged %d bytes, want %d", tt.method, tt.fallback, entry.Bytes, tt.bytes)
+		}
+		if fromHeader := entry.has("bytes_from_header"); fromHeader != (tt.bytes > 0) {
+			t.Errorf("%s with fallback %v: bytes_from_header is %v", tt.method, tt.fallback, fromHeader)
+		}
+	}
+}
+
+func TestContentLengthFallbackCounted(t *testing.T) {
+	var entry Entry
+	l, _ := newTestLogger(WithContentLengthFallback(), WithEntryWriter(entryWriterFunc(func(e Entry) error {
+		entry = e
+		return nil
+	})))
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "hello.txt", time.Time{}, strings.NewReader("hello"))
+	}, httptest.NewRequest("GET", "/", nil))
+
+	if entry.Bytes != 5 || entry.has("bytes_from_header") {
+		t.Errorf("logged %d bytes, bytes_from_header %v; want 5 counted bytes", entry.Bytes, entry.has("bytes_from_header"))
+	}
+}
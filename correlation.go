@@ -0,0 +1,54 @@
+package babylogger
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+type correlationIDKey struct{}
+
+// WithCorrelationIDHeader reads a correlation ID from the given request
+// header, like X-Correlation-ID, and logs it as correlation_id. Requests
+// without one get a new random UUID. The ID is echoed in the same response
+// header and stored in the request's context, where handlers can get it with
+// CorrelationIDFromContext to pass it on to other services.
+func WithCorrelationIDHeader(header string) Option {
+	return func(l *Logger) {
+		l.correlationHeader = http.CanonicalHeaderKey(header)
+	}
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx by
+// WithCorrelationIDHeader, or an empty string if there isn't one.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// correlate reads or generates the request's correlation ID, echoes it in
+// the response header and returns the request with the ID in its context.
+func (l *Logger) correlate(w http.ResponseWriter, r *http.Request, e *Entry) *http.Request {
+	id := r.Header.Get(l.correlationHeader)
+	if id == "" {
+		id = newUUID()
+	}
+	w.Header().Set(l.correlationHeader, id)
+	e.add("correlation_id", id)
+
+	r = r.WithContext(context.WithValue(r.Context(), correlationIDKey{}, id))
+	e.ctx = r.Context()
+	return r
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,37 @@
+package babylogger
+
+import "net/http"
+
+// WithCORSAnnotation explains CORS traffic in the log. Preflight requests,
+// OPTIONS requests with Origin and Access-Control-Request-Method headers,
+// which otherwise show up as mysterious OPTIONS requests, are logged with
+// what they ask for:
+//
+//	cors_preflight=true cors_origin=https://example.com cors_method=POST cors_headers=X-Custom-Header
+//
+// where cors_headers is only logged when the request lists headers. Other
+// cross-origin requests are logged with their cors_origin.
+func WithCORSAnnotation() Option {
+	return func(l *Logger) {
+		l.cors = true
+	}
+}
+
+// addCORS adds the CORS details of a request to an entry.
+func addCORS(r *http.Request, e *Entry) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	method := r.Header.Get("Access-Control-Request-Method")
+	if r.Method != http.MethodOptions || method == "" {
+		e.add("cors_origin", origin)
+		return
+	}
+	e.add("cors_preflight", true)
+	e.add("cors_origin", origin)
+	e.add("cors_method", method)
+	if h := r.Header.Get("Access-Control-Request-Headers"); h != "" {
+		e.add("cors_headers", h)
+	}
+}
@@ -0,0 +1,101 @@
+package babylogger
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// ContextExtractor pulls a field to log out of a request's context. Extract
+// returns the name to log the value under, or an empty key if there's
+// nothing to log.
+type ContextExtractor interface {
+	Extract(ctx context.Context) (key string, value interface{})
+}
+
+// WithContextFields logs values from the request's context, like the ones
+// frameworks and earlier middleware store there before the request reaches
+// the logger. The extractors are called when the request comes in, and what
+// they find is logged on the request line. They're called again once the
+// handler has returned, and any values that have appeared or changed since,
+// because something further down updated state it shares through the
+// context, are logged on the response line.
+//
+//	babylogger.WithContextFields(
+//		babylogger.ContextStringField(tenantKey{}, "tenant"),
+//		babylogger.ContextIntField(shardKey{}, "shard"),
+//	)
+//
+// It can be given more than once to add more extractors.
+func WithContextFields(extractors ...ContextExtractor) Option {
+	return func(l *Logger) {
+		l.ctxFields = append(l.ctxFields, extractors...)
+	}
+}
+
+// ContextStringField returns a ContextExtractor that logs the string stored
+// in the context under key as logName. Nothing is logged if the context has
+// no string under key.
+func ContextStringField(key interface{}, logName string) ContextExtractor {
+	return contextField{key: key, name: logName, ok: func(v interface{}) bool {
+		_, ok := v.(string)
+		return ok
+	}}
+}
+
+// ContextIntField returns a ContextExtractor that logs the int stored in the
+// context under key as logName. Nothing is logged if the context has no int
+// under key.
+func ContextIntField(key interface{}, logName string) ContextExtractor {
+	return contextField{key: key, name: logName, ok: func(v interface{}) bool {
+		_, ok := v.(int)
+		return ok
+	}}
+}
+
+// contextField is the ContextExtractor behind ContextStringField and
+// ContextIntField.
+type contextField struct {
+	key  interface{}
+	name string
+	ok   func(interface{}) bool
+}
+
+func (f contextField) Extract(ctx context.Context) (string, interface{}) {
+	v := ctx.Value(f.key)
+	if !f.ok(v) {
+		return "", nil
+	}
+	return f.name, v
+}
+
+// addContextFields logs the fields extracted from the incoming request's
+// context, and returns them so they can be compared once the handler is done.
+func (l *Logger) addContextFields(r *http.Request, e *Entry) []Attr {
+	var found []Attr
+	for _, x := range l.ctxFields {
+		if key, v := x.Extract(r.Context()); key != "" {
+			found = append(found, Attr{key, v})
+		}
+	}
+	e.Attrs = append(e.Attrs, found...)
+	return found
+}
+
+// addChangedContextFields logs the fields extracted from the request's
+// context that weren't there, or were different, when it came in.
+func (l *Logger) addChangedContextFields(r *http.Request, e *Entry, before []Attr) {
+outer:
+	for _, x := range l.ctxFields {
+		key, v := x.Extract(r.Context())
+		if key == "" {
+			continue
+		}
+		for _, a := range before {
+			if a.Key == key && reflect.DeepEqual(a.Value, v) {
+				continue outer
+			}
+		}
+		e.add(key, v)
+	}
+}
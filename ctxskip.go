@@ -0,0 +1,46 @@
+package babylogger
+
+import "context"
+
+// WithSkipOnContextKey doesn't log requests whose context holds a value for
+// key, other than nil or false. This lets middleware in front of the logger
+// suppress logging for some requests, like internal orchestration traffic,
+// without matching paths:
+//
+//	type quietKey struct{}
+//
+//	func quiet(next http.Handler) http.Handler {
+//		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//			if r.Header.Get("X-Internal") != "" {
+//				r = r.WithContext(context.WithValue(r.Context(), quietKey{}, true))
+//			}
+//			next.ServeHTTP(w, r)
+//		})
+//	}
+//
+//	l := babylogger.New(babylogger.WithSkipOnContextKey(quietKey{}))
+//	http.ListenAndServe(":8000", quiet(l.Middleware(mux)))
+//
+// As with any context key, use a value of an unexported type, like quietKey
+// above, rather than a string, so it can't collide with keys set by other
+// packages. Like with WithSkipPaths, EntryWriters still receive the entries.
+func WithSkipOnContextKey(key interface{}) Option {
+	return func(l *Logger) {
+		l.skipKey = key
+	}
+}
+
+// skippedByContext reports whether ctx asks for its request not to be
+// logged.
+func (l *Logger) skippedByContext(ctx context.Context) bool {
+	if l.skipKey == nil {
+		return false
+	}
+	switch v := ctx.Value(l.skipKey).(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package babylogger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type quietKey struct{}
+
+func TestSkipOnContextKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		set    bool
+		logged bool
+	}{
+		{"absent", nil, false, true},
+		{"true", true, true, false},
+		{"false", false, true, true},
+		{"other value", "yes", true, false},
+	}
+	for _, tt := range tests {
+		entries := 0
+		l, out := newTestLogger(WithSkipOnContextKey(quietKey{}), WithEntryWriter(entryWriterFunc(func(Entry) error {
+			entries++
+			return nil
+		})))
+		r := httptest.NewRequest("GET", "/", nil)
+		if tt.set {
+			r = r.WithContext(context.WithValue(r.Context(), quietKey{}, tt.value))
+		}
+		serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, r)
+
+		if logged := out.String() != ""; logged != tt.logged {
+			t.Errorf("%s: logged %v, want %v", tt.name, logged, tt.logged)
+		}
+		if entries != 1 {
+			t.Errorf("%s: %d entries written, want 1", tt.name, entries)
+		}
+	}
+}
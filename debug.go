@@ -0,0 +1,50 @@
+package babylogger
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// WithDebugTrigger logs requests carrying the given header with the given
+// value in full, no matter what: they bypass the log decider, and their
+// request line includes debug=true, the local address and server name (see
+// WithLocalAddr), the user agent and the request body size. This makes it
+// possible to replay a problematic request against a production server and
+// get its details on demand. Their response line also flags handler
+// mistakes that are otherwise glossed over, like double_write_header=true
+// when WriteHeader is called more than once.
+//
+// Anyone who knows the header and value can trigger this, which would let
+// them bypass your log filtering and inflate your logs, so treat the value
+// like a secret: pick a long random one, don't hardcode it, and rotate it if
+// it leaks. The value is compared in constant time so it can't be guessed by
+// timing responses.
+func WithDebugTrigger(header, value string) Option {
+	return func(l *Logger) {
+		l.debugHeader = header
+		l.debugValue = []byte(value)
+	}
+}
+
+// debugTriggered reports whether a request carries the debug trigger.
+func (l *Logger) debugTriggered(r *http.Request) bool {
+	if l.debugHeader == "" {
+		return false
+	}
+	got := r.Header.Get(l.debugHeader)
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), l.debugValue) == 1
+}
+
+// addDebugDetails adds the fields logged for debug requests.
+func (l *Logger) addDebugDetails(r *http.Request, e *Entry) {
+	e.add("debug", true)
+	if !l.localAddr {
+		addLocalAddr(r, e)
+	}
+	if ua := r.UserAgent(); ua != "" {
+		e.add("user_agent", ua)
+	}
+	if r.ContentLength > 0 {
+		e.add("request_bytes", r.ContentLength)
+	}
+}
@@ -0,0 +1,50 @@
+package babylogger
+
+import "net/http"
+
+// WithLogDecider sets a function that decides, after the handler has run,
+// whether a request gets logged. It receives the complete Entry, including
+// the status, byte count and duration, so the decision can depend on the
+// outcome of the request. Returning false skips both log lines.
+//
+// Because the decision is only made once the response is known, the request
+// line is held back and logged together with the response line instead of
+// when the request arrives. EntryWriters are unaffected and still receive
+// every entry.
+//
+// For example, to skip successful requests for static assets while still
+// logging any that fail:
+//
+//	l := babylogger.New(babylogger.WithLogDecider(func(e babylogger.Entry) bool {
+//		return !strings.HasPrefix(e.Path, "/static/") || e.Status >= 300
+//	}))
+func WithLogDecider(fn func(Entry) bool) Option {
+	return func(l *Logger) {
+		l.logDecider = fn
+	}
+}
+
+// shouldLog decides whether a completed request whose log lines were held
+// back gets logged.
+func (l *Logger) shouldLog(cfg *liveConfig, r *http.Request, e *Entry) bool {
+	if l.excludeStatus[e.Status] {
+		return false
+	}
+	if e.unsampled {
+		if !l.tracing.upgrade(e) {
+			return false
+		}
+		e.add("sample_upgraded", true)
+		return true
+	}
+	if l.isStatic(e.Path) && e.Status < 400 {
+		return false
+	}
+	if e.Status < cfg.minStatus {
+		return false
+	}
+	if cfg.sampler != nil && r != nil && !cfg.sampler.Sample(r, e.Status) {
+		return false
+	}
+	return l.logDecider == nil || l.logDecider(*e)
+}
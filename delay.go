@@ -0,0 +1,27 @@
+package babylogger
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithArtificialDelay sleeps for d before calling the next handler, inside
+// the time the request is logged as taking. It's a testing aid, for checking
+// where Babylogger sits in a middleware chain: when it's outermost, the
+// logged duration includes the delay plus everything the other middleware
+// does, and tests can assert that the duration is at least d.
+//
+// Don't use it in production: it slows down every request.
+func WithArtificialDelay(d time.Duration) Option {
+	return func(l *Logger) {
+		l.delay = d
+	}
+}
+
+// delayed returns next, delayed by d.
+func delayed(next http.Handler, d time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(d)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,59 @@
+package babylogger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// WithDurationColorScale colors the duration on the response line on a scale
+// from green through yellow to red, giving the log a heatmap feel. Durations
+// at or below low are green, durations at or above high are red, and those in
+// between are interpolated. When colors are disabled durations are rendered
+// plainly, as usual.
+func WithDurationColorScale(low, high time.Duration) Option {
+	return func(l *Logger) {
+		l.durationScale = &[2]time.Duration{low, high}
+	}
+}
+
+// Stops of the duration color scale.
+var durationScaleStops = [3][3]float64{
+	{0x5f, 0xd7, 0x5f}, // green
+	{0xd7, 0xd7, 0x5f}, // yellow
+	{0xd7, 0x5f, 0x5f}, // red
+}
+
+// durationStyle returns the style for rendering a given duration.
+func (l *Logger) durationStyle(t *Theme, d time.Duration) lipgloss.Style {
+	if l.durationScale == nil {
+		return t.Duration
+	}
+	low, high := l.durationScale[0], l.durationScale[1]
+
+	var f float64
+	switch {
+	case d <= low:
+		f = 0
+	case d >= high || high <= low:
+		f = 1
+	default:
+		f = float64(d-low) / float64(high-low)
+	}
+
+	// Interpolate between the two stops f falls between
+	from, to := durationScaleStops[0], durationScaleStops[1]
+	f *= 2
+	if f > 1 {
+		from, to = durationScaleStops[1], durationScaleStops[2]
+		f--
+	}
+	var rgb [3]int
+	for i := range rgb {
+		rgb[i] = int(from[i] + (to[i]-from[i])*f)
+	}
+
+	c := fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+	return t.Duration.Copy().Foreground(lipgloss.Color(c))
+}
@@ -0,0 +1,93 @@
+package babylogger
+
+import (
+	"strings"
+	"time"
+)
+
+// ecsVersion is the version of the Elastic Common Schema the ECS format
+// follows.
+const ecsVersion = "8.11"
+
+// logECS logs an entry as a single JSON object following the Elastic Common
+// Schema. The fields are documented on the ECS format constant.
+func (l *Logger) logECS(e *Entry) {
+	var o ecsObject
+	o.set("@timestamp", e.Time.UTC().Format(time.RFC3339Nano))
+	o.set("log.level", l.level(e.Status))
+	o.set("message", e.Method+" "+e.URI)
+	o.set("ecs.version", ecsVersion)
+	o.set("http.version", strings.TrimPrefix(e.Proto, "HTTP/"))
+	o.set("http.request.method", e.Method)
+	o.set("http.response.status_code", e.Status)
+	o.set("http.response.body.bytes", e.Bytes)
+	o.set("url.original", e.URI)
+	o.set("url.path", e.Path)
+	o.set("client.ip", e.RemoteAddr)
+	o.set("event.duration", e.Duration.Nanoseconds())
+
+	if l.rateLimited(e.Status) && !e.has("rate_limited") {
+		o.set("babylogger.rate_limited", true)
+	}
+	for _, a := range e.Attrs {
+		switch v := a.Value.(type) {
+		case Panic:
+			frames := make([]string, len(v.Frames))
+			for i, f := range v.Frames {
+				frames[i] = f.String()
+			}
+			o.set("error.message", v.Value)
+			o.set("error.stack_trace", strings.Join(frames, "\n"))
+			continue
+		}
+		switch a.Key {
+		case "panic":
+			o.set("error.message", a.Value)
+		case "local_addr":
+			o.set("server.address", a.Value)
+		case "server_name":
+			o.set("tls.client.server_name", a.Value)
+		default:
+			o.set("babylogger."+a.Key, a.Value)
+		}
+	}
+
+	l.writeJSON(e.Status, o)
+}
+
+// ecsObject is an ordered JSON object that can be built with dotted paths.
+type ecsObject []Attr
+
+// set sets the value at a dotted path, creating intermediate objects as
+// needed. Keys starting with @ aren't split.
+func (o *ecsObject) set(path string, v interface{}) {
+	key, rest := path, ""
+	if !strings.HasPrefix(path, "@") {
+		if dot := strings.Index(path, "."); dot != -1 {
+			key, rest = path[:dot], path[dot+1:]
+		}
+	}
+
+	for i, a := range *o {
+		if a.Key != key {
+			continue
+		}
+		if rest == "" {
+			(*o)[i].Value = v
+			return
+		}
+		child, _ := a.Value.([]Attr)
+		c := ecsObject(child)
+		c.set(rest, v)
+		(*o)[i].Value = []Attr(c)
+		return
+	}
+
+	if rest == "" {
+		*o = append(*o, Attr{key, v})
+		return
+	}
+	var c ecsObject
+	c.set(rest, v)
+	*o = append(*o, Attr{key, []Attr(c)})
+}
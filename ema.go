@@ -0,0 +1,123 @@
+package babylogger
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Defaults for WithLatencyEMA.
+const (
+	defaultEMAMinSamples = 10
+	emaMaxEndpoints      = 10000
+)
+
+// WithLatencyEMA keeps an exponential moving average of the response time
+// of each method and path, and logs it next to the duration of requests,
+// like ema_latency=32ms, which makes unusually slow requests easy to spot.
+// Requests that take more than twice the average are logged with
+// latency_spike=true too. The average is what it was before the request.
+//
+// alpha, between 0 and 1, is the weight of each new request: the higher it
+// is, the faster the average follows changes. Nothing is logged for a method
+// and path until it has been seen 10 times; see WithLatencyEMAMinSamples.
+// Averages are kept for up to 10,000 methods and paths, after which the least
+// recently seen are forgotten.
+func WithLatencyEMA(alpha float64) Option {
+	return func(l *Logger) {
+		if l.ema == nil {
+			l.ema = newLatencyEMA(defaultEMAMinSamples)
+		}
+		l.ema.alpha = alpha
+		l.ema.enabled = true
+	}
+}
+
+// WithLatencyEMAMinSamples sets how many requests to a method and path
+// WithLatencyEMA waits for before logging their average. The default is 10.
+// It only configures WithLatencyEMA, which must be given too: New panics if
+// it isn't.
+func WithLatencyEMAMinSamples(n int) Option {
+	return func(l *Logger) {
+		if l.ema == nil {
+			l.ema = newLatencyEMA(n)
+		}
+		l.ema.minSamples = n
+	}
+}
+
+// latencyEMA holds the moving averages of response times.
+type latencyEMA struct {
+	alpha      float64
+	minSamples int
+	enabled    bool // whether WithLatencyEMA was given
+
+	mtx       sync.Mutex
+	endpoints map[string]*list.Element
+	order     *list.List // front is the most recently seen
+}
+
+type endpointEMA struct {
+	key     string
+	avg     float64 // in nanoseconds
+	samples int
+}
+
+func newLatencyEMA(minSamples int) *latencyEMA {
+	return &latencyEMA{
+		minSamples: minSamples,
+		endpoints:  make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// validate checks the settings.
+func (m *latencyEMA) validate() error {
+	switch {
+	case !m.enabled:
+		return fmt.Errorf("WithLatencyEMAMinSamples requires WithLatencyEMA")
+	case m.alpha <= 0 || m.alpha > 1:
+		return fmt.Errorf("latency EMA alpha %v not between 0 and 1", m.alpha)
+	case m.minSamples < 0:
+		return fmt.Errorf("latency EMA minimum samples can't be negative")
+	}
+	return nil
+}
+
+// observe adds a request's duration to the average for its method and
+// path, and logs the average from before it.
+func (m *latencyEMA) observe(e *Entry) {
+	key := e.Method + " " + e.Path
+	d := float64(e.Duration)
+
+	m.mtx.Lock()
+	el, ok := m.endpoints[key]
+	if !ok {
+		el = m.order.PushFront(&endpointEMA{key: key, avg: d})
+		m.endpoints[key] = el
+		if m.order.Len() > emaMaxEndpoints {
+			oldest := m.order.Back()
+			m.order.Remove(oldest)
+			delete(m.endpoints, oldest.Value.(*endpointEMA).key)
+		}
+	} else {
+		m.order.MoveToFront(el)
+	}
+	ep := el.Value.(*endpointEMA)
+	prev, samples := ep.avg, ep.samples
+	if samples > 0 {
+		ep.avg = m.alpha*d + (1-m.alpha)*ep.avg
+	}
+	ep.samples++
+	m.mtx.Unlock()
+
+	if samples < m.minSamples {
+		return
+	}
+	avg := time.Duration(prev)
+	e.add("ema_latency", avg)
+	if e.Duration > 2*avg {
+		e.add("latency_spike", true)
+	}
+}
@@ -0,0 +1,34 @@
+package babylogger
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// newPanic returns what New panics with for opts, or nil.
+func newPanic(opts ...Option) (v interface{}) {
+	defer func() { v = recover() }()
+	New(append([]Option{WithOutput(io.Discard)}, opts...)...)
+	return nil
+}
+
+func TestLatencyEMAMinSamplesRequiresEMA(t *testing.T) {
+	v := newPanic(WithLatencyEMAMinSamples(5))
+	if s, _ := v.(string); !strings.Contains(s, "requires WithLatencyEMA") {
+		t.Errorf("got panic %v, want one saying WithLatencyEMA is required", v)
+	}
+
+	for _, opts := range [][]Option{
+		{WithLatencyEMAMinSamples(5), WithLatencyEMA(0.2)},
+		{WithLatencyEMA(0.2), WithLatencyEMAMinSamples(5)},
+	} {
+		if v := newPanic(opts...); v != nil {
+			t.Errorf("got panic %v", v)
+		}
+	}
+
+	if v := newPanic(WithLatencyEMA(0)); v == nil || strings.Contains(v.(string), "requires") {
+		t.Errorf("got panic %v for an alpha of 0", v)
+	}
+}
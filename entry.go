@@ -0,0 +1,105 @@
+package babylogger
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is the record of a single request and its response. It's what gets
+// rendered to the log, and what's handed to EntryWriters.
+type Entry struct {
+	Time       time.Time     // when the request was received
+	Method     string        // request method
+	URI        string        // request URI, as sent by the client
+	Path       string        // URL path, without the query
+	Proto      string        // protocol, e.g. HTTP/1.1
+	RemoteAddr string        // client address, without the port
+	Status     int           // response status code
+	Bytes      int           // response body bytes written
+	Duration   time.Duration // time spent in the handler
+
+	// Attrs holds any extra fields added by options, in the order they were
+	// added.
+	Attrs []Attr
+
+	split      int             // Attrs[:split] were logged on the request line
+	retryAfter string          // Retry-After header for rate limited requests
+	ctx        context.Context // the request's context
+	gcp        *gcpTrace       // details for the GCP format
+	nginx      *nginxRequest   // details for the Nginx format
+	unsampled  bool            // not sampled by WithSampledTracing
+	bodyDump   *bodyDump       // the body to log, for BodyCapturingMiddleware
+	child      *slog.Logger    // the request's logger, from WithChildLogger
+}
+
+// Attr is an extra key/value field on an Entry.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns the attribute formatted as key=value. String values that
+// contain spaces, quotes or equals signs are quoted.
+func (a Attr) String() string {
+	v := fmt.Sprint(a.Value)
+	if v == "" || strings.ContainsAny(v, " \"=") {
+		v = strconv.Quote(v)
+	}
+	return a.Key + "=" + v
+}
+
+// add appends an attribute to the entry.
+func (e *Entry) add(key string, value interface{}) {
+	e.Attrs = append(e.Attrs, Attr{key, value})
+}
+
+// has reports whether the entry has an attribute with the given key.
+func (e *Entry) has(key string) bool {
+	for _, a := range e.Attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// requestAttrs returns the attributes that belong on the request line.
+func (e *Entry) requestAttrs() []Attr {
+	return e.Attrs[:e.split]
+}
+
+// responseAttrs returns the attributes that belong on the response line.
+func (e *Entry) responseAttrs() []Attr {
+	return e.Attrs[e.split:]
+}
+
+// EntryWriter receives an Entry for every completed request. It's the hook
+// for shipping entries somewhere other than the log, like a database or a
+// message queue. WriteEntry is called synchronously at the end of each
+// request, so implementations that do I/O should buffer or hand entries off
+// to another goroutine.
+type EntryWriter interface {
+	WriteEntry(Entry) error
+}
+
+// WithEntryWriter adds an EntryWriter that receives every completed request.
+// It can be given more than once to add multiple writers.
+func WithEntryWriter(w EntryWriter) Option {
+	return func(l *Logger) {
+		l.entryWriters = append(l.entryWriters, w)
+	}
+}
+
+// writeEntry hands a completed entry to the configured EntryWriters.
+func (l *Logger) writeEntry(e *Entry) {
+	for _, w := range l.entryWriters {
+		if err := w.WriteEntry(*e); err != nil {
+			log.Printf("babylogger: error writing entry: %v", err)
+		}
+	}
+}
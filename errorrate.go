@@ -0,0 +1,80 @@
+package babylogger
+
+import (
+	"sync"
+	"time"
+)
+
+// errorRateWindow is the longest window ErrorRate looks back over.
+const errorRateWindow = 10 * time.Minute
+
+// ErrorRate returns the share of requests completed in the last window that
+// got a 5xx response, from 0 to 1, or 0 if there weren't any requests. It's
+// meant for readiness checks that report a service as degraded when it's
+// failing:
+//
+//	http.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+//		if l.ErrorRate(time.Minute) > 0.05 {
+//			http.Error(w, "degraded", http.StatusServiceUnavailable)
+//		}
+//	})
+//
+// Requests are counted per second, so window is rounded up to whole seconds
+// and the current, partial second is included. Only the last ten minutes are
+// kept; longer windows are cut to ten minutes.
+func (l *Logger) ErrorRate(window time.Duration) float64 {
+	return l.recent.rate(time.Now(), window)
+}
+
+// outcomes counts requests and 5xx responses per second over a rolling
+// window.
+type outcomes struct {
+	mtx     sync.Mutex
+	buckets [errorRateWindow / time.Second]outcomeBucket
+}
+
+type outcomeBucket struct {
+	second         int64 // the Unix second the counts are for
+	total, errored int64
+}
+
+// record counts a request completed at t.
+func (o *outcomes) record(t time.Time, code int) {
+	sec := t.Unix()
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	b := &o.buckets[sec%int64(len(o.buckets))]
+	if b.second != sec {
+		*b = outcomeBucket{second: sec}
+	}
+	b.total++
+	if code >= 500 {
+		b.errored++
+	}
+}
+
+// rate returns the share of 5xx responses in the window ending at now.
+func (o *outcomes) rate(now time.Time, window time.Duration) float64 {
+	if window > errorRateWindow {
+		window = errorRateWindow
+	}
+	secs := int64((window + time.Second - 1) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	end := now.Unix()
+
+	var total, errored int64
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	for _, b := range o.buckets {
+		if b.second > end-secs && b.second <= end {
+			total += b.total
+			errored += b.errored
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(errored) / float64(total)
+}
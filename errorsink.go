@@ -0,0 +1,122 @@
+package babylogger
+
+import (
+	"io"
+	"log"
+	"regexp"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// WithErrorSink sends the log lines of responses with a status of minStatus
+// or above to w, and everything else to the usual output. For example, to
+// log 4xx and 5xx responses to stderr:
+//
+//	babylogger.WithErrorSink(os.Stderr, 400)
+//
+// With the pretty format only response lines are sent to w, as the status
+// isn't known yet when the request line is logged. Errors logged through
+// ServerErrorLog are treated as 500s. Lines to w are rendered without colors
+// if w isn't a terminal, regardless of the usual output.
+//
+// Every line is written with a single Write call, so when both outputs share
+// a file lines don't interleave, and they appear in the order they were
+// logged as long as neither writer buffers. WithBufferedOutput and
+// WithLogTimeout apply to w as well as to the usual output, each with its
+// own buffer and queue, so with either of them lines to the two outputs
+// may be written out of order.
+func WithErrorSink(w io.Writer, minStatus int) Option {
+	return func(l *Logger) {
+		l.errSink = &errorSink{
+			w:         w,
+			logger:    log.New(w, "", log.LstdFlags),
+			minStatus: minStatus,
+			colorless: isColorless(w),
+		}
+	}
+}
+
+// errorSink is the output set with WithErrorSink.
+type errorSink struct {
+	w         io.Writer
+	logger    *log.Logger
+	minStatus int
+	colorless bool
+
+	// buffered and timeoutOut are set when w is wrapped for
+	// WithBufferedOutput and WithLogTimeout.
+	buffered   *bufferedWriter
+	timeoutOut *timeoutWriter
+}
+
+// setWriter makes the sink write through w, which wraps its writer.
+func (s *errorSink) setWriter(w io.Writer) {
+	s.w = w
+	s.logger = log.New(w, s.logger.Prefix(), s.logger.Flags())
+}
+
+// flushers returns the sink's writers that buffer, outermost first.
+func (s *errorSink) flushers() []interface{} {
+	var fs []interface{}
+	if s.timeoutOut != nil {
+		fs = append(fs, s.timeoutOut)
+	}
+	if s.buffered != nil {
+		fs = append(fs, s.buffered)
+	}
+	return fs
+}
+
+// close writes out what the sink's writers hold and stops their goroutines.
+func (s *errorSink) close() {
+	if s.timeoutOut != nil {
+		s.timeoutOut.close()
+	}
+	if s.buffered != nil {
+		s.buffered.close()
+	}
+}
+
+// isColorless reports whether w can't render colors.
+func isColorless(w io.Writer) bool {
+	if jb, ok := w.(JSONBackend); ok && jb.IsJSONOutput() {
+		return true
+	}
+	return lipgloss.NewRenderer(w).ColorProfile() == termenv.Ascii
+}
+
+// sinkFor returns the error sink lines of responses with the given status
+// go to, or nil if they go to the usual output.
+func (l *Logger) sinkFor(status int) *errorSink {
+	if l.errSink == nil || status < l.errSink.minStatus {
+		return nil
+	}
+	return l.errSink
+}
+
+// printStatus logs a pretty line about a response with the given status.
+func (l *Logger) printStatus(status int, line string) {
+	s := l.sinkFor(status)
+	if s == nil {
+		l.print(line)
+		return
+	}
+	if s.colorless {
+		line = stripANSI(line)
+	}
+	if l.location != nil {
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		s.w.Write(l.logLine(s.logger, line))
+		return
+	}
+	s.logger.Print(line)
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+// stripANSI removes ANSI escape sequences, like colors, from s.
+func stripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
@@ -0,0 +1,61 @@
+package babylogger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fail(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func TestErrorSinkBuffered(t *testing.T) {
+	out, sink := new(syncBuffer), new(syncBuffer)
+	l := New(WithOutput(out), WithNoColor(), WithErrorSink(sink, 500), WithBufferedOutput(time.Hour))
+	serveTest(l, fail, httptest.NewRequest("GET", "/first", nil))
+
+	if got := sink.String(); got != "" {
+		t.Fatalf("error sink written before the flush interval:\n%s", got)
+	}
+	if err := l.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := sink.String(); !strings.Contains(got, "-> 500") {
+		t.Fatalf("error sink not flushed:\n%s", got)
+	}
+
+	serveTest(l, fail, httptest.NewRequest("GET", "/second", nil))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := lines(sink); len(got) != 2 {
+		t.Errorf("got %d lines in the error sink after Close, want 2:\n%s", len(got), sink.String())
+	}
+	if got := out.String(); !strings.Contains(got, "<- GET /second") || strings.Contains(got, "-> 500") {
+		t.Errorf("unexpected output:\n%s", got)
+	}
+}
+
+func TestErrorSinkLogTimeout(t *testing.T) {
+	sink := &blockingWriter{release: make(chan struct{})}
+	l := New(WithOutput(new(syncBuffer)), WithErrorSink(sink, 500), WithLogTimeout(10*time.Millisecond))
+	defer l.Close()
+	defer close(sink.release)
+
+	const requests = logQueueSize + 10
+	start := time.Now()
+	for i := 0; i < requests; i++ {
+		serveTest(l, fail, httptest.NewRequest("GET", "/", nil))
+	}
+
+	if elapsed, max := time.Since(start), requests*10*time.Millisecond+time.Second; elapsed > max {
+		t.Errorf("requests took %v with a blocked error sink, want under %v", elapsed, max)
+	}
+	if dropped := l.Stats().Dropped; dropped == 0 {
+		t.Error("no lines dropped with a blocked error sink")
+	}
+}
@@ -0,0 +1,92 @@
+package babylogger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// logEvent logs a line that isn't a request or response line, like streaming
+// progress. If the event is about a request, e is that request's entry;
+// otherwise it's nil.
+func (l *Logger) logEvent(e *Entry, event string, attrs ...Attr) {
+	now := l.now()
+
+	var logger *slog.Logger
+	if e != nil {
+		logger = l.slogger(e)
+	} else {
+		logger = l.slog
+	}
+	if logger != nil {
+		ctx := context.Background()
+		if e != nil && e.ctx != nil {
+			ctx = e.ctx
+		}
+		sa := make([]slog.Attr, 0, len(attrs)+2)
+		if e != nil {
+			sa = append(sa, slog.String("method", e.Method), slog.String("uri", e.URI))
+		}
+		for _, a := range attrs {
+			sa = append(sa, slog.Any(a.Key, a.Value))
+		}
+		logger.LogAttrs(ctx, slog.LevelInfo, event, sa...)
+		return
+	}
+
+	switch l.format {
+	case JSON:
+		n := l.fieldNames
+		var fields []Attr
+		if n.Time != "" {
+			fields = append(fields, Attr{n.Time, now.Format(time.RFC3339Nano)})
+		}
+		if n.Level != "" {
+			fields = append(fields, Attr{n.Level, "info"})
+		}
+		fields = append(fields, Attr{"event", event})
+		if e != nil {
+			if n.Method != "" {
+				fields = append(fields, Attr{n.Method, e.Method})
+			}
+			if n.URI != "" {
+				fields = append(fields, Attr{n.URI, e.URI})
+			}
+		}
+		l.writeJSON(0, append(fields, attrs...))
+
+	case GCP:
+		if e != nil {
+			attrs = append([]Attr{{"method", e.Method}, {"uri", e.URI}}, attrs...)
+		}
+		l.logGCPLine(0, e, now, "info", event, attrs)
+
+	case Nginx:
+		// nginx's access log has no room for events
+
+	case ECS:
+		var o ecsObject
+		o.set("@timestamp", now.UTC().Format(time.RFC3339Nano))
+		o.set("log.level", "info")
+		o.set("message", event)
+		o.set("ecs.version", ecsVersion)
+		o.set("event.action", event)
+		if e != nil {
+			o.set("http.request.method", e.Method)
+			o.set("url.original", e.URI)
+		}
+		for _, a := range attrs {
+			o.set("babylogger."+a.Key, a.Value)
+		}
+		l.writeJSON(0, o)
+
+	default:
+		t := l.styles()
+		line := t.Subtle.Render("~>")
+		if e != nil {
+			line += " " + l.methodStyle(t, e.Method).Render(e.Method) + " " + t.URI.Render(e.URI)
+		}
+		line += " " + t.Subtle.Render(event) + l.renderAttrs(attrs)
+		l.print(line)
+	}
+}
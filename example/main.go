@@ -1,12 +1,21 @@
 package main
 
 import (
+	"flag"
 	"net/http"
+	"os"
 
 	"github.com/meowgorithm/babylogger"
 )
 
 func main() {
+	preview := flag.Bool("preview", false, "preview the default theme and exit")
+	flag.Parse()
+
+	if *preview {
+		babylogger.PreviewTheme(os.Stdout, babylogger.DefaultTheme())
+		return
+	}
 
 	// HTTP server with Babylogger middleware
 	http.Handle("/", babylogger.Middleware(http.HandlerFunc(handler)))
@@ -0,0 +1,34 @@
+// This example shows how to correlate what handlers log with Babylogger's
+// lines by putting the request ID in the log prefix. It prints something
+// like:
+//
+//	2009/11/10 23:00:00 <- GET /users/42 127.0.0.1 request_id=1k3XhbMz5aE
+//	2009/11/10 23:00:00 [1k3XhbMz5aE] loading user 42
+//	2009/11/10 23:00:00 -> 200 OK 6B 84µs
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/meowgorithm/babylogger"
+)
+
+func main() {
+	l := babylogger.New(
+		babylogger.WithSnowflakeRequestID(1),
+		babylogger.WithRequestIDInLogPrefix(),
+	)
+
+	srv := httptest.NewServer(l.Middleware(http.HandlerFunc(handler)))
+	defer srv.Close()
+
+	http.Get(srv.URL + "/users/42")
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	logger := babylogger.LoggerFromContext(r.Context())
+	logger.Printf("loading user %s", strings.TrimPrefix(r.URL.Path, "/users/"))
+	w.Write([]byte("oh hey"))
+}
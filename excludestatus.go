@@ -0,0 +1,19 @@
+package babylogger
+
+// WithExcludeStatusCodes doesn't log requests whose response has one of the
+// given statuses, for expected and noisy ones like 304 Not Modified from
+// caching or 101 Switching Protocols from WebSockets. Unlike WithMinStatus
+// it matches exact codes. The request line is held back until the response
+// is known. EntryWriters still receive every entry.
+//
+// It can be given more than once to exclude more codes.
+func WithExcludeStatusCodes(codes ...int) Option {
+	return func(l *Logger) {
+		if l.excludeStatus == nil {
+			l.excludeStatus = make(map[int]bool, len(codes))
+		}
+		for _, code := range codes {
+			l.excludeStatus[code] = true
+		}
+	}
+}
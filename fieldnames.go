@@ -0,0 +1,80 @@
+package babylogger
+
+import "fmt"
+
+// FieldNames maps the fields of structured log lines to the keys they're
+// written with, so output can match the schema an ingestion system expects.
+// An empty name omits the field.
+type FieldNames struct {
+	Time       string
+	Level      string
+	Message    string // a short summary, like GET /path
+	Method     string
+	URI        string
+	Path       string
+	Proto      string
+	RemoteAddr string
+	Status     string
+	Bytes      string
+	Duration   string
+}
+
+// DefaultFieldNames returns the field names used by default.
+func DefaultFieldNames() FieldNames {
+	return FieldNames{
+		Time:       "time",
+		Level:      "level",
+		Method:     "method",
+		URI:        "uri",
+		Path:       "path",
+		Proto:      "proto",
+		RemoteAddr: "remote_addr",
+		Status:     "status",
+		Bytes:      "bytes",
+		Duration:   "duration",
+	}
+}
+
+// ECSFieldNames returns field names following the Elastic Common Schema. Keys
+// are written in dotted form, which Elasticsearch expands into objects.
+func ECSFieldNames() FieldNames {
+	return FieldNames{
+		Time:       "@timestamp",
+		Level:      "log.level",
+		Message:    "message",
+		Method:     "http.request.method",
+		URI:        "url.original",
+		Path:       "url.path",
+		Proto:      "http.version",
+		RemoteAddr: "client.ip",
+		Status:     "http.response.status_code",
+		Bytes:      "http.response.body.bytes",
+		Duration:   "event.duration",
+	}
+}
+
+// WithFieldNames sets the keys used for fields in structured log lines. New
+// panics if two fields are given the same name.
+func WithFieldNames(names FieldNames) Option {
+	return func(l *Logger) {
+		l.fieldNames = names
+	}
+}
+
+// validate checks that no two fields share a name.
+func (n FieldNames) validate() error {
+	seen := make(map[string]bool)
+	for _, name := range []string{
+		n.Time, n.Level, n.Message, n.Method, n.URI, n.Path, n.Proto,
+		n.RemoteAddr, n.Status, n.Bytes, n.Duration,
+	} {
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate field name %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
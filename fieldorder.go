@@ -0,0 +1,96 @@
+package babylogger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Field is a column of the pretty format's log lines.
+type Field int
+
+// Available fields. The method, URI and remote address appear on the request
+// line; the status, byte count and duration on the response line.
+const (
+	FieldMethod Field = iota
+	FieldURI
+	FieldRemoteAddr
+	FieldStatus
+	FieldBytes
+	FieldDuration
+	numFields
+)
+
+// String returns the name of the field.
+func (f Field) String() string {
+	switch f {
+	case FieldMethod:
+		return "method"
+	case FieldURI:
+		return "uri"
+	case FieldRemoteAddr:
+		return "remote_addr"
+	case FieldStatus:
+		return "status"
+	case FieldBytes:
+		return "bytes"
+	case FieldDuration:
+		return "duration"
+	}
+	return fmt.Sprintf("Field(%d)", int(f))
+}
+
+// DefaultFieldOrder returns the order fields appear in by default.
+func DefaultFieldOrder() []Field {
+	return []Field{FieldMethod, FieldURI, FieldRemoteAddr, FieldStatus, FieldBytes, FieldDuration}
+}
+
+// WithFieldOrder sets the order fields appear in on the pretty format's
+// request and response lines. Fields that aren't listed are left out, so this
+// can be used to trim lines too. For example, to log only the status and the
+// duration of responses, with a request line that leads with the URI:
+//
+//	babylogger.WithFieldOrder(
+//		babylogger.FieldURI,
+//		babylogger.FieldMethod,
+//		babylogger.FieldDuration,
+//		babylogger.FieldStatus,
+//	)
+//
+// Fields always stay on their own line, so the relative order of request and
+// response fields doesn't matter. Attributes added by other options follow
+// the fields. New panics if a field is unknown or listed twice.
+func WithFieldOrder(fields ...Field) Option {
+	return func(l *Logger) {
+		l.fieldOrder = fields
+	}
+}
+
+// validateFieldOrder checks a field order for unknown and duplicate fields.
+func validateFieldOrder(fields []Field) error {
+	var seen [numFields]bool
+	for _, f := range fields {
+		if f < 0 || f >= numFields {
+			return fmt.Errorf("unknown field %s", f)
+		}
+		if seen[f] {
+			return fmt.Errorf("field %s listed twice", f)
+		}
+		seen[f] = true
+	}
+	if len(fields) == 0 {
+		return errors.New("field order is empty")
+	}
+	return nil
+}
+
+// columns returns the rendered fields in order, skipping the ones that
+// aren't in cols, which holds a line's fields.
+func (l *Logger) columns(cols map[Field]string) []string {
+	out := make([]string, 0, len(cols))
+	for _, f := range l.fieldOrder {
+		if c, ok := cols[f]; ok {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package babylogger
+
+import "context"
+
+// Flush blocks until log entries buffered by the default Logger have been
+// written, or ctx is done. See Logger.Flush.
+func Flush(ctx context.Context) error {
+	return std.Flush(ctx)
+}
+
+// Flush blocks until buffered log entries have been written, or ctx is done,
+// in which case ctx's error is returned. It's meant for graceful shutdowns and
+// for tests that inspect the log.
+//
+// Flush drains the output and EntryWriters that buffer. They take part by
+// implementing either of:
+//
+//	Flush(context.Context) error
+//	Flush() error
+//
+// When nothing is buffered Flush is a no-op.
+func (l *Logger) Flush(ctx context.Context) error {
+	var flushers []interface{}
+	if l.timeoutOut != nil {
+		flushers = append(flushers, l.timeoutOut)
+	}
+	flushers = append(flushers, l.out)
+	if l.errSink != nil {
+		flushers = append(flushers, l.errSink.flushers()...)
+	}
+	for _, w := range l.entryWriters {
+		flushers = append(flushers, w)
+	}
+	for _, f := range flushers {
+		if err := flush(ctx, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the goroutines started by the Logger's options, after logging
+// anything they have pending, like the last health check summary or lines
+// queued by WithLogTimeout or buffered by WithBufferedOutput. Closing a
+// Logger doesn't affect its middleware, which keeps working.
+func (l *Logger) Close() error {
+	if l.health != nil {
+		l.health.close()
+	}
+	if l.timeoutOut != nil {
+		l.timeoutOut.close()
+	}
+	if l.buffered != nil {
+		l.buffered.close()
+	}
+	if l.errSink != nil {
+		l.errSink.close()
+	}
+	return nil
+}
+
+// flush flushes v, if it's a flusher.
+func flush(ctx context.Context, v interface{}) error {
+	switch f := v.(type) {
+	case interface{ Flush(context.Context) error }:
+		return f.Flush(ctx)
+	case interface{ Flush() error }:
+		done := make(chan error, 1)
+		go func() {
+			done <- f.Flush()
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
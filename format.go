@@ -0,0 +1,264 @@
+package babylogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Format is the format log lines are written in.
+type Format int
+
+// Available formats.
+const (
+	// Pretty logs two colorful lines per request: one when the request
+	// comes in and one when the response goes out. This is the default.
+	Pretty Format = iota
+
+	// JSON logs a single JSON object per request once the response has been
+	// sent. Lines are written directly to the output (by default the
+	// standard logger's), without a prefix, so each line is valid JSON.
+	// Durations are numbers, in nanoseconds unless set otherwise with
+	// WithStructuredDurationUnit.
+	JSON
+
+	// ECS logs a single JSON object per request following the Elastic
+	// Common Schema (ECS), with fields nested under the namespaces
+	// Elasticsearch expects. The fields are:
+	//
+	//	@timestamp                 when the request was received
+	//	log.level                  info, warn or error, by status
+	//	message                    method and URI, e.g. GET /users
+	//	ecs.version                the ECS version followed
+	//	http.version               e.g. 1.1
+	//	http.request.method        request method
+	//	http.response.status_code  response status
+	//	http.response.body.bytes   response body size
+	//	url.original               request URI, including the query
+	//	url.path                   URL path
+	//	client.ip                  client address
+	//	event.duration             time spent in the handler, in nanoseconds
+	//
+	// Some fields only appear when the corresponding option is enabled:
+	//
+	//	server.address             WithLocalAddr
+	//	tls.client.server_name     WithLocalAddr, for TLS requests
+	//	error.message              WithPanicRecovery, when a panic is recovered
+	//	error.stack_trace          WithPanicDetails, when a panic is recovered
+	//
+	// Any other fields added by options are nested under babylogger, e.g.
+	// babylogger.timed_out.
+	ECS
+
+	// GCP logs a single JSON object per request in the structured logging
+	// format of Google Cloud Logging. See WithGoogleCloudLogging, which sets
+	// it up.
+	GCP
+
+	// Nginx logs a single line per request in nginx's default combined
+	// format, so existing tooling for nginx logs keeps working:
+	//
+	//	$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent"
+	//
+	// For example:
+	//
+	//	127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 2326 "https://example.com/" "curl/8.4.0"
+	//
+	// $remote_user is - unless WithRemoteUser is given, and so are a missing
+	// referer and user agent.
+	// $body_bytes_sent is the size of the response body. Quotes, backslashes
+	// and non-printable bytes are escaped as \xHH, like nginx does.
+	//
+	// Fields added by options aren't logged, nor are events like
+	// WithStreamingProgress's, as they don't fit the format. Server errors
+	// are written in the format of nginx's error log.
+	Nginx
+)
+
+// WithFormat sets the format log lines are written in.
+func WithFormat(f Format) Option {
+	return func(l *Logger) {
+		l.format = f
+	}
+}
+
+// logJSON logs an entry as a single JSON object.
+func (l *Logger) logJSON(e *Entry) {
+	n := l.fieldNames
+	var fields []Attr
+	field := func(name string, value interface{}) {
+		if name != "" {
+			fields = append(fields, Attr{name, value})
+		}
+	}
+	field(n.Time, e.Time.Format(time.RFC3339Nano))
+	field(n.Level, l.level(e.Status))
+	field(n.Message, e.Method+" "+e.URI)
+	field(n.Method, e.Method)
+	field(n.URI, e.URI)
+	field(n.Path, e.Path)
+	field(n.Proto, e.Proto)
+	field(n.RemoteAddr, e.RemoteAddr)
+	field(n.Status, e.Status)
+	field(n.Bytes, e.Bytes)
+	field(n.Duration, e.Duration)
+
+	if l.rateLimited(e.Status) && !e.has("rate_limited") {
+		fields = append(fields, Attr{"rate_limited", true})
+	}
+	fields = append(fields, e.Attrs...)
+
+	l.writeJSON(e.Status, fields)
+}
+
+// WithJSONEscapeHTML sets whether the JSON based formats escape <, > and &
+// in strings, like encoding/json does by default to make JSON safe to embed
+// in HTML. Logs aren't HTML, so by default they're left as is, keeping URIs
+// like /search?q=a&page=2 readable instead of /search?q=a\u0026page=2.
+func WithJSONEscapeHTML(escape bool) Option {
+	return func(l *Logger) {
+		l.jsonEscapeHTML = escape
+	}
+}
+
+// DurationUnit is the unit durations are logged in by the JSON based
+// formats.
+type DurationUnit int
+
+// Available duration units.
+const (
+	// Nanoseconds logs durations as whole nanoseconds, like ECS expects.
+	// This is the default.
+	Nanoseconds DurationUnit = iota
+
+	// Microseconds logs durations as fractional microseconds.
+	Microseconds
+
+	// Milliseconds logs durations as fractional milliseconds.
+	Milliseconds
+)
+
+// WithStructuredDurationUnit sets the unit durations are logged in by the
+// JSON based formats. Durations are logged as numbers, so consumers don't
+// have to parse units: in nanoseconds by default, like duration=1234567, or
+// as fractional microseconds or milliseconds, like duration=1.234567 for
+// Milliseconds. This applies to the duration field, named as set with
+// WithFieldNames, and to every other duration, like ttfb; the field names
+// stay the same whatever the unit. The pretty format keeps logging
+// durations as text, like 1.2ms.
+func WithStructuredDurationUnit(u DurationUnit) Option {
+	return func(l *Logger) {
+		l.durationUnit = u
+	}
+}
+
+// jsonEncoding holds the settings fields are encoded as JSON with.
+type jsonEncoding struct {
+	escapeHTML   bool
+	durationUnit DurationUnit
+}
+
+// duration returns d as a number in the unit.
+func (enc jsonEncoding) duration(d time.Duration) interface{} {
+	switch enc.durationUnit {
+	case Microseconds:
+		return float64(d) / float64(time.Microsecond)
+	case Milliseconds:
+		return float64(d) / float64(time.Millisecond)
+	}
+	return d.Nanoseconds()
+}
+
+// encodeJSON encodes fields as a JSON object, keeping them in order, followed
+// by a newline. Values that are themselves []Attr are encoded as nested
+// objects. Durations are encoded as numbers, in the unit of enc.
+func encodeJSON(fields []Attr, enc jsonEncoding) ([]byte, error) {
+	var b bytes.Buffer
+	if err := encodeObject(&b, fields, enc); err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func encodeObject(b *bytes.Buffer, fields []Attr, enc jsonEncoding) error {
+	b.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := marshal(f.Key, enc.escapeHTML)
+		if err != nil {
+			return err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+
+		if obj, ok := f.Value.([]Attr); ok {
+			if err := encodeObject(b, obj, enc); err != nil {
+				return err
+			}
+			continue
+		}
+
+		v := f.Value
+		if d, ok := v.(time.Duration); ok {
+			v = enc.duration(d)
+		}
+		val, err := marshal(v, enc.escapeHTML)
+		if err != nil {
+			return fmt.Errorf("encoding %s: %v", f.Key, err)
+		}
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return nil
+}
+
+// marshal encodes v as JSON, escaping HTML characters in strings if
+// escapeHTML is set.
+func marshal(v interface{}, escapeHTML bool) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(escapeHTML)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte{'\n'}), nil
+}
+
+// writeJSON encodes fields as a JSON object and writes it as a line. status
+// is the status of the response the line is about, if any, or 0.
+func (l *Logger) writeJSON(status int, fields []Attr) {
+	b, err := encodeJSON(fields, jsonEncoding{l.jsonEscapeHTML, l.durationUnit})
+	if err != nil {
+		log.Printf("babylogger: error encoding entry: %v", err)
+		return
+	}
+	l.writeFor(status, b)
+}
+
+// writeFor writes a complete line about a response with the given status, or
+// 0, to the error sink if it takes the status, or else to the output.
+func (l *Logger) writeFor(status int, b []byte) {
+	if s := l.sinkFor(status); s != nil {
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		s.w.Write(b)
+		return
+	}
+	l.writeLine(b)
+}
+
+// writeLine writes a complete line to the output.
+func (l *Logger) writeLine(b []byte) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.timeoutOut != nil {
+		l.timeoutOut.Write(b)
+		return
+	}
+	l.writer().Write(b)
+}
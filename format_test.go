@@ -0,0 +1,66 @@
+package babylogger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONEscapeHTML(t *testing.T) {
+	const uri = "/a?b=1&c=<2>"
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	l, out := newTestLogger(WithFormat(JSON))
+	serveTest(l, ok, httptest.NewRequest("GET", uri, nil))
+	if got := out.String(); !strings.Contains(got, `"uri":"`+uri+`"`) {
+		t.Errorf("URI isn't logged literally:\n%s", got)
+	}
+
+	l, out = newTestLogger(WithFormat(JSON), WithJSONEscapeHTML(true))
+	serveTest(l, ok, httptest.NewRequest("GET", uri, nil))
+	if got := out.String(); !strings.Contains(got, `"uri":"/a?b=1\u0026c=\u003c2\u003e"`) {
+		t.Errorf("URI isn't escaped with WithJSONEscapeHTML(true):\n%s", got)
+	}
+}
+
+func TestStructuredDurationUnit(t *testing.T) {
+	const d = 1234567 * time.Nanosecond
+	tests := []struct {
+		unit DurationUnit
+		want float64
+	}{
+		{Nanoseconds, 1234567},
+		{Microseconds, 1234.567},
+		{Milliseconds, 1.234567},
+	}
+	for _, tt := range tests {
+		l, out := newTestLogger(WithFormat(JSON), WithStructuredDurationUnit(tt.unit))
+		l.logJSON(&Entry{Method: "GET", URI: "/", Status: 200, Duration: d, Attrs: []Attr{{"ttfb", d}}})
+
+		var line map[string]interface{}
+		if err := json.Unmarshal([]byte(out.String()), &line); err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range []string{"duration", "ttfb"} {
+			got, ok := line[field].(float64)
+			if !ok {
+				t.Errorf("unit %d: %s is %#v, not a number", tt.unit, field, line[field])
+				continue
+			}
+			if got != tt.want {
+				t.Errorf("unit %d: %s is %v, want %v", tt.unit, field, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestPrettyDurationIsText(t *testing.T) {
+	l, out := newTestLogger(WithStructuredDurationUnit(Milliseconds))
+	l.logResponse(&Entry{Status: 200, Duration: 1500 * time.Microsecond})
+	if got := out.String(); !strings.Contains(got, "1.5ms") {
+		t.Errorf("pretty line doesn't have the duration as text:\n%s", got)
+	}
+}
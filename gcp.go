@@ -0,0 +1,132 @@
+package babylogger
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithGoogleCloudLogging logs requests as JSON in the structured logging
+// format of Google Cloud Logging, which Cloud Run, App Engine and GKE pick up
+// from stdout. Each request is logged with an httpRequest object, so the Log
+// Viewer shows it as a request, and with the trace of the request, from the
+// X-Cloud-Trace-Context or traceparent header, so the Log Viewer groups it
+// with application logs from the same trace. projectID is the Google Cloud
+// project the traces belong to.
+func WithGoogleCloudLogging(projectID string) Option {
+	return func(l *Logger) {
+		l.format = GCP
+		l.gcpProject = projectID
+	}
+}
+
+// gcpTrace holds the request details only the GCP format logs.
+type gcpTrace struct {
+	traceID   string
+	spanID    string
+	sampled   bool
+	userAgent string
+	referer   string
+}
+
+// readGCPTrace reads the trace context and other details the GCP format logs
+// from a request.
+func readGCPTrace(r *http.Request) *gcpTrace {
+	t := &gcpTrace{userAgent: r.UserAgent(), referer: r.Referer()}
+
+	// X-Cloud-Trace-Context: TRACE_ID/SPAN_ID;o=OPTIONS
+	if h := r.Header.Get("X-Cloud-Trace-Context"); h != "" {
+		trace, opts, _ := strings.Cut(h, ";")
+		t.traceID, t.spanID, _ = strings.Cut(trace, "/")
+		if t.spanID != "" {
+			// The span ID is decimal here, but hex everywhere else
+			if n, err := strconv.ParseUint(t.spanID, 10, 64); err == nil {
+				t.spanID = fmt.Sprintf("%016x", n)
+			}
+		}
+		t.sampled = opts == "o=1"
+		return t
+	}
+
+	// traceparent: VERSION-TRACE_ID-SPAN_ID-FLAGS
+	if h := r.Header.Get("traceparent"); h != "" {
+		parts := strings.Split(h, "-")
+		if len(parts) == 4 && len(parts[1]) == 32 && len(parts[2]) == 16 {
+			t.traceID, t.spanID = parts[1], parts[2]
+			t.sampled = strings.HasSuffix(parts[3], "1")
+		}
+	}
+	return t
+}
+
+// gcpSeverity returns the Cloud Logging severity for a level.
+func gcpSeverity(level string) string {
+	switch level {
+	case "warn":
+		return "WARNING"
+	case "error":
+		return "ERROR"
+	}
+	return "INFO"
+}
+
+// gcpTraceFields returns the fields that tie a log entry to a trace.
+func (l *Logger) gcpTraceFields(e *Entry) []Attr {
+	if e == nil || e.gcp == nil || e.gcp.traceID == "" {
+		return nil
+	}
+	fields := []Attr{{"logging.googleapis.com/trace", "projects/" + l.gcpProject + "/traces/" + e.gcp.traceID}}
+	if e.gcp.spanID != "" {
+		fields = append(fields, Attr{"logging.googleapis.com/spanId", e.gcp.spanID})
+	}
+	return append(fields, Attr{"logging.googleapis.com/trace_sampled", e.gcp.sampled})
+}
+
+// logGCP logs an entry in the Cloud Logging structured format.
+func (l *Logger) logGCP(e *Entry) {
+	req := []Attr{
+		{"requestMethod", e.Method},
+		{"requestUrl", e.URI},
+		{"status", e.Status},
+		{"responseSize", strconv.Itoa(e.Bytes)},
+		{"remoteIp", e.RemoteAddr},
+		{"protocol", e.Proto},
+		{"latency", fmt.Sprintf("%.9fs", e.Duration.Seconds())},
+	}
+	if e.gcp != nil {
+		if e.gcp.userAgent != "" {
+			req = append(req, Attr{"userAgent", e.gcp.userAgent})
+		}
+		if e.gcp.referer != "" {
+			req = append(req, Attr{"referer", e.gcp.referer})
+		}
+	}
+
+	fields := []Attr{
+		{"time", e.Time.Format(time.RFC3339Nano)},
+		{"severity", gcpSeverity(l.level(e.Status))},
+		{"message", e.Method + " " + e.URI},
+		{"httpRequest", req},
+	}
+	fields = append(fields, l.gcpTraceFields(e)...)
+	if l.rateLimited(e.Status) && !e.has("rate_limited") {
+		fields = append(fields, Attr{"rate_limited", true})
+	}
+	fields = append(fields, e.Attrs...)
+
+	l.writeJSON(e.Status, fields)
+}
+
+// logGCPLine logs a line that isn't about a request's response in the Cloud
+// Logging structured format.
+func (l *Logger) logGCPLine(status int, e *Entry, now time.Time, level, message string, attrs []Attr) {
+	fields := []Attr{
+		{"time", now.Format(time.RFC3339Nano)},
+		{"severity", gcpSeverity(level)},
+		{"message", message},
+	}
+	fields = append(fields, l.gcpTraceFields(e)...)
+	l.writeJSON(status, append(fields, attrs...))
+}
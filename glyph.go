@@ -0,0 +1,36 @@
+package babylogger
+
+import "strconv"
+
+// statusGlyph is the bullet WithStatusGlyph renders in place of the status
+// text.
+const statusGlyph = "●"
+
+// WithStatusGlyph shortens the status on the pretty response line to a
+// bullet, colored like the status class, and the status code:
+//
+//	-> ● 200 512B 1.2ms
+//
+// instead of:
+//
+//	-> 200 OK 512B 1.2ms
+//
+// It has no effect on the other formats, nor with WithMinimal or
+// WithNoColor, where the bullet would carry no information.
+func WithStatusGlyph() Option {
+	return func(l *Logger) {
+		l.statusGlyph = true
+	}
+}
+
+// renderStatus renders the status on the pretty response line.
+func (l *Logger) renderStatus(code int) string {
+	if l.statusGlyph && !l.config().noColor {
+		return l.statusStyle(code).Render(statusGlyph) + " " + strconv.Itoa(code)
+	}
+	status := strconv.Itoa(code)
+	if text := l.statusText(code); text != "" {
+		status += " " + text
+	}
+	return l.statusStyle(code).Render(status)
+}
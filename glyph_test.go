@@ -0,0 +1,41 @@
+package babylogger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatusGlyphColor(t *testing.T) {
+	theme := DefaultTheme().renderer(colorRenderer())
+	l := New(WithTheme(theme), WithStatusGlyph())
+
+	tests := []struct {
+		code  int
+		style string
+	}{
+		{200, theme.Status2xx.Render(statusGlyph)},
+		{301, theme.Status3xx.Render(statusGlyph)},
+		{404, theme.Status4xx.Render(statusGlyph)},
+		{429, theme.Status429.Render(statusGlyph)},
+		{503, theme.Status5xx.Render(statusGlyph)},
+	}
+	for _, tt := range tests {
+		got := l.renderStatus(tt.code)
+		if !strings.HasPrefix(got, tt.style) {
+			t.Errorf("%d rendered as %q, want it to start with %q", tt.code, got, tt.style)
+		}
+		if strings.Contains(got, l.statusText(tt.code)) {
+			t.Errorf("%d rendered as %q, with its status text", tt.code, got)
+		}
+	}
+	if a, b := l.renderStatus(200), l.renderStatus(503); a[:strings.Index(a, statusGlyph)] == b[:strings.Index(b, statusGlyph)] {
+		t.Error("200 and 503 glyphs have the same color")
+	}
+}
+
+func TestStatusGlyphNoColor(t *testing.T) {
+	l := New(WithStatusGlyph(), WithNoColor())
+	if got := l.renderStatus(404); got != "404 Not Found" {
+		t.Errorf("got %q without colors, want 404 Not Found", got)
+	}
+}
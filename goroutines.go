@@ -0,0 +1,47 @@
+package babylogger
+
+import (
+	"net/http"
+	"runtime"
+)
+
+// WithGoroutineCount logs the number of goroutines when each request starts
+// and ends, like goroutines_start=100 goroutines_end=102. The numbers are
+// process-wide, so concurrent requests blur them, but a count that keeps
+// growing request after request points to a leak.
+func WithGoroutineCount() Option {
+	return func(l *Logger) {
+		l.goroutineCount = true
+	}
+}
+
+// WithGoroutineLeakAlert calls fn when the number of goroutines grew by more
+// than threshold while a request was served, with the request and by how much
+// it grew. It's meant for alerting on suspected leaks, e.g. by incrementing a
+// metric. Like WithGoroutineCount, the count is process-wide, so set the
+// threshold above the noise of concurrent requests. fn is called
+// synchronously, before the response is logged.
+func WithGoroutineLeakAlert(threshold int, fn func(r *http.Request, delta int)) Option {
+	return func(l *Logger) {
+		l.leakThreshold = threshold
+		l.leakAlert = fn
+	}
+}
+
+// countGoroutines reports whether goroutines need to be counted.
+func (l *Logger) countGoroutines() bool {
+	return l.goroutineCount || l.leakAlert != nil
+}
+
+// checkGoroutines logs the goroutine counts and calls the leak alert if the
+// count grew past the threshold.
+func (l *Logger) checkGoroutines(r *http.Request, e *Entry, start int) {
+	end := runtime.NumGoroutine()
+	if l.goroutineCount {
+		e.add("goroutines_start", start)
+		e.add("goroutines_end", end)
+	}
+	if l.leakAlert != nil && end-start > l.leakThreshold {
+		l.leakAlert(r, end-start)
+	}
+}
@@ -0,0 +1,83 @@
+// Package grpc logs gRPC calls with Babylogger's look and feel. It lives in
+// its own package so the gRPC dependency is only pulled in by programs that
+// use it.
+//
+// This is a separate code path from the HTTP middleware: calls are logged on a
+// single line with the full gRPC method, the gRPC status code and the call's
+// duration. Status codes are colored by class: OK is green, codes caused by
+// the client are colored like 4xx responses and server-side failures like
+// 5xx responses.
+//
+// Example:
+//
+//	l := babylogger.New()
+//	s := grpc.NewServer(
+//		grpc.UnaryInterceptor(babygrpc.UnaryServerInterceptor(l)),
+//	)
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/meowgorithm/babylogger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns an interceptor that logs every unary RPC
+// with the given Logger.
+func UnaryServerInterceptor(l *babylogger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		rpc := babylogger.RPC{
+			Method:   info.FullMethod,
+			Code:     code.String(),
+			Status:   HTTPStatus(code),
+			Duration: time.Since(start),
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			rpc.PeerAddr = p.Addr.String()
+		}
+		l.LogRPC(rpc)
+
+		return resp, err
+	}
+}
+
+// HTTPStatus maps a gRPC status code to the closest HTTP status code. It's
+// used to pick the color a code is rendered in.
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default: // Unknown, Internal, DataLoss
+		return http.StatusInternalServerError
+	}
+}
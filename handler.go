@@ -0,0 +1,40 @@
+package babylogger
+
+import "net/http"
+
+// WithHandler sets the handler a Logger serves when it's used directly as an
+// http.Handler. See Logger.ServeHTTP.
+func WithHandler(h http.Handler) Option {
+	return func(l *Logger) {
+		l.handler = h
+	}
+}
+
+// ServeHTTP implements http.Handler, so a Logger can be used as a server's
+// handler directly:
+//
+//	l := babylogger.New(babylogger.WithHandler(mux))
+//	http.ListenAndServe(":8000", l)
+//
+// Requests are logged and passed to the handler set with WithHandler. Like
+// http.Server, if there's no handler http.DefaultServeMux is used.
+func (l *Logger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	next := l.handler
+	if next == nil {
+		next = http.DefaultServeMux
+	}
+	if r != nil && l.bypassed(r.Method) {
+		next.ServeHTTP(w, r)
+		return
+	}
+	l.serve(w, r, next)
+}
+
+// Then returns the logging middleware wrapping next. It's the same as
+// Middleware, for use with routers and middleware chains that expect the
+// Then naming:
+//
+//	http.ListenAndServe(":8000", l.Then(mux))
+func (l *Logger) Then(next http.Handler) http.Handler {
+	return l.Middleware(next)
+}
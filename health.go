@@ -0,0 +1,148 @@
+package babylogger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithHealthCheckSummary stops logging requests to the given paths, like
+// /healthz, one by one, and instead logs a summary of them every interval:
+//
+//	~> health_checks path=/healthz requests=600 status=200 period=5m0s
+//
+// When the responses had different statuses, status lists the count for each,
+// like 200:598,503:2. This keeps probes from drowning out other requests
+// while still showing that they're coming in and how they went. Requests
+// carrying a debug trigger (see WithDebugTrigger) are still logged.
+//
+// The summaries are logged from a goroutine, which stops when the Logger is
+// closed. See Logger.Close.
+func WithHealthCheckSummary(interval time.Duration, paths ...string) Option {
+	return func(l *Logger) {
+		h := &healthChecks{
+			interval: interval,
+			paths:    make(map[string]bool, len(paths)),
+			counts:   make(map[string]map[int]int),
+			done:     make(chan struct{}),
+			stopped:  make(chan struct{}),
+		}
+		for _, p := range paths {
+			h.paths[p] = true
+		}
+		l.health = h
+	}
+}
+
+// healthChecks counts health check requests between summaries.
+type healthChecks struct {
+	interval time.Duration
+	paths    map[string]bool
+
+	mtx    sync.Mutex
+	counts map[string]map[int]int // path -> status -> requests
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func (h *healthChecks) validate() error {
+	if h.interval <= 0 {
+		return errors.New("health check summary interval must be positive")
+	}
+	return nil
+}
+
+// match reports whether requests to path are health checks.
+func (h *healthChecks) match(path string) bool {
+	return h != nil && h.paths[path]
+}
+
+// record counts a health check request.
+func (h *healthChecks) record(path string, status int) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.counts[path] == nil {
+		h.counts[path] = make(map[int]int)
+	}
+	h.counts[path][status]++
+}
+
+// take returns the counts so far and resets them.
+func (h *healthChecks) take() map[string]map[int]int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	counts := h.counts
+	h.counts = make(map[string]map[int]int)
+	return counts
+}
+
+func (h *healthChecks) close() {
+	h.closeOnce.Do(func() {
+		close(h.done)
+	})
+	<-h.stopped
+}
+
+// summarizeHealthChecks logs health check summaries until the Logger is
+// closed.
+func (l *Logger) summarizeHealthChecks() {
+	h := l.health
+	defer close(h.stopped)
+
+	t := time.NewTicker(h.interval)
+	defer t.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-h.done:
+			l.logHealthChecks(h.take(), time.Since(last).Round(time.Millisecond))
+			return
+		case last = <-t.C:
+			l.logHealthChecks(h.take(), h.interval)
+		}
+	}
+}
+
+// logHealthChecks logs a summary line for each health check path that got
+// requests.
+func (l *Logger) logHealthChecks(counts map[string]map[int]int, period time.Duration) {
+	paths := make([]string, 0, len(counts))
+	for p := range counts {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		statuses := make([]int, 0, len(counts[p]))
+		var total int
+		for status, n := range counts[p] {
+			statuses = append(statuses, status)
+			total += n
+		}
+		sort.Ints(statuses)
+
+		var status string
+		if len(statuses) == 1 {
+			status = fmt.Sprint(statuses[0])
+		} else {
+			parts := make([]string, len(statuses))
+			for i, s := range statuses {
+				parts[i] = fmt.Sprintf("%d:%d", s, counts[p][s])
+			}
+			status = strings.Join(parts, ",")
+		}
+
+		l.logEvent(nil, "health_checks",
+			Attr{"path", p},
+			Attr{"requests", total},
+			Attr{"status", status},
+			Attr{"period", period},
+		)
+	}
+}
@@ -0,0 +1,96 @@
+package babylogger
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithUncompressedByteCount logs the uncompressed size of gzip and deflate
+// encoded responses as bytes, and the size that went over the wire as
+// compressed_bytes:
+//
+//	-> 200 OK 8.2kB 2ms compressed_bytes=1024
+//
+// Sizes are more meaningful for analytics this way, as they don't depend on
+// how well a response compressed or whether the client asked for
+// compression at all.
+//
+// This doesn't come for free: each compressed response is decompressed as
+// it's written, which costs about as much CPU as compressing it did, so
+// think twice before turning it on for a busy service. The bodies themselves
+// are never kept. Responses that fail to decompress are logged with their
+// compressed size.
+func WithUncompressedByteCount() Option {
+	return func(l *Logger) {
+		l.uncompressed = true
+	}
+}
+
+// inflateCounter counts the uncompressed bytes of a compressed response
+// body. The body is decompressed in another goroutine, fed through a pipe,
+// as the decompressors only work on readers.
+type inflateCounter struct {
+	started bool
+	pw      *io.PipeWriter
+	done    chan struct{}
+	n       int64
+	err     error
+}
+
+// write feeds p to the decompressor. The first call decides from the
+// response's Content-Encoding whether there's anything to decompress.
+func (c *inflateCounter) write(h http.Header, p []byte) {
+	if !c.started {
+		c.started = true
+		switch strings.ToLower(strings.TrimSpace(h.Get("Content-Encoding"))) {
+		case "gzip", "x-gzip":
+			c.start(func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) })
+		case "deflate":
+			c.start(func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) })
+		}
+	}
+	if c.pw != nil {
+		c.pw.Write(p)
+	}
+}
+
+// start starts decompressing what's written with the decompressor returned
+// by open.
+func (c *inflateCounter) start(open func(io.Reader) (io.Reader, error)) {
+	pr, pw := io.Pipe()
+	c.pw = pw
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		zr, err := open(pr)
+		if err == nil {
+			c.n, err = io.Copy(io.Discard, zr)
+		}
+		c.err = err
+		// Keep reading whatever's left, so writes never block
+		io.Copy(io.Discard, pr)
+	}()
+}
+
+// finish waits for the decompressor to finish and returns the uncompressed
+// size. It reports false if the response wasn't compressed or didn't
+// decompress.
+func (c *inflateCounter) finish() (int64, bool) {
+	if c.pw == nil {
+		return 0, false
+	}
+	c.pw.Close()
+	<-c.done
+	return c.n, c.err == nil
+}
+
+// stop closes the pipe to the decompressor, if it was started, so its
+// goroutine ends even when finish is never called.
+func (c *inflateCounter) stop() {
+	if c.pw != nil {
+		c.pw.Close()
+	}
+}
@@ -0,0 +1,64 @@
+package babylogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestUncompressedByteCount(t *testing.T) {
+	body := strings.Repeat("babylogger ", 100)
+	z := gzipped(t, body)
+	l, out := newTestLogger(WithUncompressedByteCount())
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(z)
+	}, httptest.NewRequest("GET", "/", nil))
+
+	if got := out.String(); !strings.Contains(got, "1.1kB") || !strings.Contains(got, "compressed_bytes=") {
+		t.Errorf("uncompressed size not logged:\n%s", got)
+	}
+}
+
+func TestUncompressedByteCountPanic(t *testing.T) {
+	z := gzipped(t, strings.Repeat("babylogger ", 100))
+	l, _ := newTestLogger(WithUncompressedByteCount())
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		func() {
+			defer func() { recover() }()
+			serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "gzip")
+				w.Write(z[:len(z)/2])
+				panic("boom")
+			}, httptest.NewRequest("GET", "/", nil))
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left running after panics, had %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,40 @@
+package babylogger
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WithInformational logs the informational 1xx responses a handler sends
+// before its final one, like 103 Early Hints, each on a line of its own as
+// they're sent, with the Link headers they carry:
+//
+//	~> GET / informational status=103 link="</app.css>; rel=preload; as=style"
+//
+// This helps debug Early Hints and preload setups, where the hints are
+// otherwise invisible in the logs. 101 Switching Protocols is a final
+// response as far as Babylogger is concerned, and is logged as usual; see
+// WithUpgradeLogging.
+//
+// Sending 1xx responses by calling WriteHeader more than once needs Go 1.19
+// or later, for both HTTP/1.1 and HTTP/2. Lines are only logged for
+// responses the handler sends through the logger's writer, and not for the
+// 100 Continue net/http sends on its own when a handler reads the body of a
+// request that expects one.
+func WithInformational() Option {
+	return func(l *Logger) {
+		l.informational = true
+	}
+}
+
+// informationalHook returns the hook that logs a request's informational
+// responses.
+func (l *Logger) informationalHook(e *Entry) func(code int, h http.Header) {
+	return func(code int, h http.Header) {
+		attrs := []Attr{{"status", code}}
+		if links := h.Values("Link"); len(links) > 0 {
+			attrs = append(attrs, Attr{"link", strings.Join(links, ", ")})
+		}
+		l.logEvent(e, "informational", attrs...)
+	}
+}
@@ -0,0 +1,76 @@
+package babylogger
+
+// liveConfig holds the settings that can be changed while a Logger is in
+// use. It's replaced as a whole on every change, so requests see a
+// consistent set of settings.
+type liveConfig struct {
+	noColor   bool
+	minStatus int
+	sampler   Sampler
+	skipPaths map[string]bool
+}
+
+// WithMinStatus only logs requests whose response status is at least
+// status, e.g. 400 to only log errors. The request line is held back until
+// the response is known. EntryWriters still receive every entry. It can be
+// changed later with SetMinStatus.
+func WithMinStatus(status int) Option {
+	return func(l *Logger) {
+		l.settings.minStatus = status
+	}
+}
+
+// WithSkipPaths doesn't log requests to the given paths, which need to
+// match exactly. EntryWriters still receive their entries. They can be
+// changed later with SetSkipPaths.
+func WithSkipPaths(paths ...string) Option {
+	return func(l *Logger) {
+		l.settings.skipPaths = pathSet(paths)
+	}
+}
+
+// config returns the current live settings.
+func (l *Logger) config() *liveConfig {
+	return l.live.Load().(*liveConfig)
+}
+
+// reconfigure changes the live settings with fn.
+func (l *Logger) reconfigure(fn func(c *liveConfig)) {
+	l.liveMtx.Lock()
+	defer l.liveMtx.Unlock()
+	c := *l.config()
+	fn(&c)
+	l.live.Store(&c)
+}
+
+// SetNoColor turns colors off or back on. See WithNoColor.
+func (l *Logger) SetNoColor(noColor bool) {
+	l.reconfigure(func(c *liveConfig) { c.noColor = noColor })
+}
+
+// SetMinStatus sets the minimum response status of requests to log, or 0 to
+// log all of them. See WithMinStatus.
+func (l *Logger) SetMinStatus(status int) {
+	l.reconfigure(func(c *liveConfig) { c.minStatus = status })
+}
+
+// SetSampler sets the Sampler that decides which requests get logged, or nil
+// to log all of them. See WithSampler.
+func (l *Logger) SetSampler(s Sampler) {
+	l.reconfigure(func(c *liveConfig) { c.sampler = s })
+}
+
+// SetSkipPaths sets the paths of requests not to log, replacing the previous
+// ones. See WithSkipPaths.
+func (l *Logger) SetSkipPaths(paths ...string) {
+	set := pathSet(paths)
+	l.reconfigure(func(c *liveConfig) { c.skipPaths = set })
+}
+
+func pathSet(paths []string) map[string]bool {
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		set[p] = true
+	}
+	return set
+}
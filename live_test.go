@@ -0,0 +1,69 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestLiveReconfigureRace changes the live settings while requests are being
+// served. Run it with -race.
+func TestLiveReconfigureRace(t *testing.T) {
+	l, _ := newTestLogger()
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	const n = 100
+	var wg sync.WaitGroup
+	for _, path := range []string{"/", "/fail", "/skip"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+			}
+		}(path)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			l.SetNoColor(i%2 == 0)
+			l.SetMinStatus(i % 600)
+			l.SetSampler(RateSampler(0.5))
+			l.SetSkipPaths("/skip")
+			if i%10 == 0 {
+				l.SetSampler(nil)
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestLiveReconfigure(t *testing.T) {
+	l, out := newTestLogger()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	l.SetMinStatus(400)
+	serveTest(l, ok, httptest.NewRequest("GET", "/min", nil))
+	l.SetMinStatus(0)
+	l.SetSkipPaths("/skip")
+	serveTest(l, ok, httptest.NewRequest("GET", "/skip", nil))
+	l.SetSkipPaths()
+	serveTest(l, ok, httptest.NewRequest("GET", "/logged", nil))
+
+	got := out.String()
+	for _, path := range []string{"/min", "/skip"} {
+		if strings.Contains(got, path) {
+			t.Errorf("%s was logged:\n%s", path, got)
+		}
+	}
+	if !strings.Contains(got, "/logged") {
+		t.Errorf("/logged wasn't logged:\n%s", got)
+	}
+}
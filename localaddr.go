@@ -0,0 +1,32 @@
+package babylogger
+
+import (
+	"net"
+	"net/http"
+)
+
+// WithLocalAddr logs the local address that received each request, e.g.
+// local_addr=127.0.0.1:8080, which is useful for servers listening on several
+// interfaces or ports. For TLS requests the server name the client asked for
+// via SNI is logged too, as server_name.
+//
+// The local address comes from the http.LocalAddrContextKey value the
+// standard library's server stores in each request's context, or from the
+// connection with ConnContextMiddleware.
+func WithLocalAddr() Option {
+	return func(l *Logger) {
+		l.localAddr = true
+	}
+}
+
+// addLocalAddr adds the local address and SNI server name to an entry.
+func addLocalAddr(r *http.Request, e *Entry) {
+	if conn, ok := connAddrsFromContext(r.Context()); ok && conn.local != nil {
+		e.add("local_addr", conn.local.String())
+	} else if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		e.add("local_addr", addr.String())
+	}
+	if r.TLS != nil && r.TLS.ServerName != "" {
+		e.add("server_name", r.TLS.ServerName)
+	}
+}
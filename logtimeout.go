@@ -0,0 +1,171 @@
+package babylogger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// logQueueSize is how many lines WithLogTimeout queues for writers that
+// don't support write deadlines.
+const logQueueSize = 64
+
+// WithLogTimeout bounds how long writing a log line may block the request
+// being logged. Lines that can't be written in time are dropped and counted
+// in Stats.Dropped. This keeps a slow log destination, like syslog over the
+// network, from slowing down requests.
+//
+// If the output supports write deadlines, like a net.Conn, lines are written
+// directly with a deadline. Otherwise they're handed to a goroutine that
+// writes them in order, and a line is dropped when the goroutine has fallen
+// so far behind that it can't be handed over within d. That goroutine stops
+// when the Logger is closed. See Logger.Close. Lines for WithErrorSink's
+// writer are bounded the same way.
+func WithLogTimeout(d time.Duration) Option {
+	return func(l *Logger) {
+		l.logTimeout = d
+	}
+}
+
+// deadliner is implemented by writers that support write deadlines.
+type deadliner interface {
+	SetWriteDeadline(time.Time) error
+}
+
+// timeoutWriter writes to out, or the Logger's output if it's nil, dropping
+// writes that take longer than timeout.
+type timeoutWriter struct {
+	l       *Logger
+	out     io.Writer
+	timeout time.Duration
+
+	queue    chan queuedLine
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// queuedLine is a line waiting to be written. Flushes queue a line without
+// bytes, whose written channel is closed once the lines before it have been
+// written.
+type queuedLine struct {
+	b       []byte
+	written chan struct{}
+}
+
+// startLogTimeout sets up the Logger's output, and its error sink if it has
+// one, to apply its log timeout.
+func (l *Logger) startLogTimeout() {
+	w := newTimeoutWriter(l, nil)
+	l.timeoutOut = w
+	if s := l.errSink; s != nil {
+		s.timeoutOut = newTimeoutWriter(l, s.w)
+		s.setWriter(s.timeoutOut)
+	}
+
+	// Pretty lines keep the prefix and flags of the logger they'd otherwise
+	// have been printed with
+	if l.logger != nil {
+		l.logger = log.New(w, l.logger.Prefix(), l.logger.Flags())
+	} else {
+		l.logger = log.New(w, log.Prefix(), log.Flags())
+	}
+}
+
+// newTimeoutWriter returns a timeoutWriter for out, or the Logger's output if
+// out is nil.
+func newTimeoutWriter(l *Logger, out io.Writer) *timeoutWriter {
+	w := &timeoutWriter{
+		l:       l,
+		out:     out,
+		timeout: l.logTimeout,
+		queue:   make(chan queuedLine, logQueueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// writer returns the writer lines are written to.
+func (w *timeoutWriter) writer() io.Writer {
+	if w.out != nil {
+		return w.out
+	}
+	return w.l.writer()
+}
+
+func (w *timeoutWriter) Write(p []byte) (int, error) {
+	out := w.writer()
+	if d, ok := out.(deadliner); ok {
+		if err := d.SetWriteDeadline(time.Now().Add(w.timeout)); err == nil {
+			defer d.SetWriteDeadline(time.Time{})
+			n, err := out.Write(p)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				atomic.AddInt64(&w.l.dropped, 1)
+			}
+			return n, err
+		}
+	}
+
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	t := time.NewTimer(w.timeout)
+	defer t.Stop()
+
+	select {
+	case w.queue <- queuedLine{b: b}:
+	case <-t.C:
+		atomic.AddInt64(&w.l.dropped, 1)
+	case <-w.done:
+		// Closed; write it ourselves
+		return out.Write(p)
+	}
+	return len(p), nil
+}
+
+// run writes queued lines until the writer is closed.
+func (w *timeoutWriter) run() {
+	for {
+		select {
+		case q := <-w.queue:
+			if q.written != nil {
+				close(q.written)
+				continue
+			}
+			w.writer().Write(q.b)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Flush waits until the queued lines have been written, or ctx is done.
+func (w *timeoutWriter) Flush(ctx context.Context) error {
+	written := make(chan struct{})
+	select {
+	case w.queue <- queuedLine{written: written}:
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-written:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close writes the queued lines and stops the writer's goroutine.
+func (w *timeoutWriter) close() {
+	w.stopOnce.Do(func() {
+		w.Flush(context.Background())
+		close(w.done)
+	})
+}
@@ -0,0 +1,66 @@
+package babylogger
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// blockingWriter is a log output whose writes block until it's released.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestLogTimeoutSlowWriter(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	l := New(WithOutput(out), WithLogTimeout(10*time.Millisecond))
+	defer l.Close()
+	defer close(out.release)
+
+	const requests = logQueueSize
+	start := time.Now()
+	for i := 0; i < requests; i++ {
+		serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+	}
+
+	// Each request logs two lines. Once the queue is full, every line waits
+	// for the timeout at most.
+	if elapsed, max := time.Since(start), 2*requests*10*time.Millisecond+time.Second; elapsed > max {
+		t.Errorf("requests took %v with a blocked writer, want under %v", elapsed, max)
+	}
+	if dropped := l.Stats().Dropped; dropped == 0 {
+		t.Error("no lines dropped with a blocked writer")
+	}
+}
+
+func TestLogTimeoutDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	// Nothing reads from the pipe, so writes block until their deadline
+	l := New(WithOutput(client), WithLogTimeout(10*time.Millisecond))
+	defer l.Close()
+
+	done := make(chan struct{})
+	go func() {
+		serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("request blocked on a writer with deadlines")
+	}
+	if dropped := l.Stats().Dropped; dropped != 2 {
+		t.Errorf("dropped %d lines, want 2", dropped)
+	}
+}
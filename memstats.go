@@ -0,0 +1,33 @@
+package babylogger
+
+// WithMemStats logs how much memory was allocated while each request was
+// served, like alloc_bytes=8192 alloc_objects=54, from the difference in
+// runtime.MemStats before and after it. It's meant for load testing and
+// optimization work.
+//
+// Reading memory stats stops the world, which is too expensive for
+// production, so this only works in programs built with the
+// expensive_tracing build tag:
+//
+//	go build -tags expensive_tracing
+//
+// Without it, WithMemStats does nothing. Memory stats are process-wide, so
+// the numbers include allocations by anything running at the same time:
+// they're only accurate when requests are served one at a time.
+func WithMemStats() Option {
+	return func(l *Logger) {
+		l.memStats = memStatsAvailable
+	}
+}
+
+// memSnapshot is the total memory allocated at some point.
+type memSnapshot struct {
+	bytes, objects uint64
+}
+
+// addMemStats logs the memory allocated since before.
+func addMemStats(e *Entry, before memSnapshot) {
+	after := readMemStats()
+	e.add("alloc_bytes", after.bytes-before.bytes)
+	e.add("alloc_objects", after.objects-before.objects)
+}
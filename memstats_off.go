@@ -0,0 +1,11 @@
+//go:build !expensive_tracing
+
+package babylogger
+
+// memStatsAvailable is whether WithMemStats works in this build.
+const memStatsAvailable = false
+
+// readMemStats is never called without the expensive_tracing build tag.
+func readMemStats() memSnapshot {
+	return memSnapshot{}
+}
@@ -0,0 +1,15 @@
+//go:build expensive_tracing
+
+package babylogger
+
+import "runtime"
+
+// memStatsAvailable is whether WithMemStats works in this build.
+const memStatsAvailable = true
+
+// readMemStats returns the memory allocated so far.
+func readMemStats() memSnapshot {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return memSnapshot{bytes: m.TotalAlloc, objects: m.Mallocs}
+}
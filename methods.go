@@ -0,0 +1,92 @@
+package babylogger
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// WithMethodColors colors the method on the request line by HTTP method, for
+// example to make destructive calls stand out:
+//
+//	babylogger.WithMethodColors(map[string]lipgloss.TerminalColor{
+//		http.MethodGet:    lipgloss.Color("240"),
+//		http.MethodDelete: lipgloss.Color("196"),
+//	})
+//
+// Methods are matched case-insensitively. Methods that aren't listed use the
+// theme's Method style.
+func WithMethodColors(colors map[string]lipgloss.TerminalColor) Option {
+	return func(l *Logger) {
+		l.methodColors = make(map[string]lipgloss.TerminalColor, len(colors))
+		for m, c := range colors {
+			l.methodColors[strings.ToUpper(m)] = c
+		}
+	}
+}
+
+// WithMethodStyles renders the method on the request line with a style of
+// its own for each HTTP method, which makes logs easier to scan. Use
+// DefaultMethodStyles for a ready-made palette:
+//
+//	babylogger.WithMethodStyles(babylogger.DefaultMethodStyles())
+//
+// Methods are matched case-insensitively. Methods that aren't listed use the
+// color set with WithMethodColors, if any, or else the theme's Method style.
+func WithMethodStyles(styles map[string]lipgloss.Style) Option {
+	return func(l *Logger) {
+		l.methodStyles = make(map[string]lipgloss.Style, len(styles))
+		for m, s := range styles {
+			l.methodStyles[strings.ToUpper(m)] = s
+		}
+	}
+}
+
+// DefaultMethodStyles returns a palette of method styles for
+// WithMethodStyles: GET in green, POST in blue, PUT in cyan, PATCH in yellow
+// and DELETE in red.
+func DefaultMethodStyles() map[string]lipgloss.Style {
+	style := func(light, dark string) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: light, Dark: dark})
+	}
+	return map[string]lipgloss.Style{
+		http.MethodGet:    style("28", "48"),
+		http.MethodPost:   style("26", "75"),
+		http.MethodPut:    style("30", "51"),
+		http.MethodPatch:  style("136", "220"),
+		http.MethodDelete: style("160", "204"),
+	}
+}
+
+// plainMethodStyles returns copies of styles that render without colors.
+func plainMethodStyles(styles map[string]lipgloss.Style) map[string]lipgloss.Style {
+	if styles == nil {
+		return nil
+	}
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.Ascii)
+	plain := make(map[string]lipgloss.Style, len(styles))
+	for m, s := range styles {
+		plain[m] = s.Copy().Renderer(r)
+	}
+	return plain
+}
+
+// methodStyle returns the style for rendering a given request method.
+func (l *Logger) methodStyle(t *Theme, method string) lipgloss.Style {
+	method = strings.ToUpper(method)
+	styles := l.methodStyles
+	if t == &l.plain {
+		styles = l.plainMethodStyles
+	}
+	if s, ok := styles[method]; ok {
+		return s
+	}
+	if c, ok := l.methodColors[method]; ok {
+		return t.Method.Copy().Foreground(c)
+	}
+	return t.Method
+}
@@ -0,0 +1,59 @@
+package babylogger
+
+import (
+	"io"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// colorRenderer returns a renderer for 256 colors on a dark background,
+// whatever the test's output is.
+func colorRenderer() *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.ANSI256)
+	r.SetHasDarkBackground(true)
+	return r
+}
+
+func TestMethodColors(t *testing.T) {
+	theme := DefaultTheme().renderer(colorRenderer())
+	l := New(WithTheme(theme), WithMethodColors(map[string]lipgloss.TerminalColor{
+		"DELETE": lipgloss.Color("196"),
+	}))
+	red := theme.Method.Copy().Foreground(lipgloss.Color("196"))
+
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"DELETE", red.Render("DELETE")},
+		{"delete", red.Render("delete")},
+		{"GET", theme.Method.Render("GET")},
+	}
+	for _, tt := range tests {
+		got := l.methodStyle(&l.theme, tt.method).Render(tt.method)
+		if got != tt.want {
+			t.Errorf("%s rendered as %q, want %q", tt.method, got, tt.want)
+		}
+	}
+	if red.Render("DELETE") == theme.Method.Render("DELETE") {
+		t.Fatal("configured color renders the same as the default")
+	}
+}
+
+func TestMethodStylesIgnoreCase(t *testing.T) {
+	r := colorRenderer()
+	theme := DefaultTheme().renderer(r)
+	styles := DefaultMethodStyles()
+	for m, s := range styles {
+		styles[m] = s.Copy().Renderer(r)
+	}
+	l := New(WithTheme(theme), WithMethodStyles(styles))
+
+	got := l.methodStyle(&l.theme, "delete").Render("delete")
+	if want := styles["DELETE"].Render("delete"); got != want {
+		t.Errorf("delete rendered as %q, want %q", got, want)
+	}
+}
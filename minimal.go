@@ -0,0 +1,37 @@
+package babylogger
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithMinimal is a preset for the smallest useful logs, like on embedded
+// devices. Each request is logged on a single line once the response has
+// been sent, without colors, with exactly these fields:
+//
+//	GET /users 200 1.2ms
+//
+// That's the method, the path (without the query), the status code and the
+// duration, rounded to three significant digits. Attributes added by other
+// options follow, as usual. WithMinimal only affects the pretty format.
+func WithMinimal() Option {
+	return func(l *Logger) {
+		WithNoColor()(l)
+		l.minimal = true
+	}
+}
+
+// minimalLine renders the line WithMinimal logs for an entry.
+func (l *Logger) minimalLine(e *Entry) string {
+	return fmt.Sprintf("%s %s %d %s", e.Method, e.Path, e.Status, shortDuration(e.Duration)) +
+		l.renderAttrs(e.Attrs)
+}
+
+// shortDuration rounds d to three significant digits.
+func shortDuration(d time.Duration) string {
+	unit := time.Duration(1)
+	for d/unit >= 1000 {
+		unit *= 10
+	}
+	return d.Round(unit).String()
+}
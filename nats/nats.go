@@ -0,0 +1,299 @@
+// Package nats streams Babylogger entries to NATS, for event-driven
+// architectures where access logs are consumed by other services. Each entry
+// is published as a JSON message on a subject that can be routed by request
+// metadata.
+//
+// Example:
+//
+//	w, err := nats.New(nats.NATSConfig{
+//		URL:     "nats://localhost:4222",
+//		Subject: "http.logs.{method}.{status_class}",
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer w.Close()
+//
+//	l := babylogger.New(babylogger.WithEntryWriter(w))
+//	http.ListenAndServe(":8000", l.Middleware(mux))
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meowgorithm/babylogger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Defaults.
+const (
+	DefaultSubject    = "http.logs.{method}.{status_class}"
+	DefaultBufferSize = 1024
+)
+
+// NATSConfig configures a NATSWriter.
+type NATSConfig struct {
+	// URL is the NATS server to connect to. Defaults to nats.DefaultURL.
+	URL string
+
+	// Subject is the subject entries are published on. It may contain the
+	// placeholders {method}, {status} and {status_class} (e.g. 2xx), which
+	// are filled in per entry.
+	Subject string
+
+	// QueueGroup is the queue group used by Subscribe, so that consumers in
+	// the same group share the stream of entries.
+	QueueGroup string
+
+	// BufferSize is how many entries can be waiting to be published before
+	// new ones are dropped.
+	BufferSize int
+
+	// Options are passed to nats.Connect.
+	Options []nats.Option
+}
+
+// NATSWriter is a babylogger.EntryWriter that publishes entries to NATS. If a
+// JetStream stream covers the subject, entries are published to it for
+// durable delivery; otherwise they're published with core NATS.
+//
+// Publishing never blocks the request path: entries are queued and published
+// from a separate goroutine. While the connection is down, or if the queue is
+// full, entries are dropped and counted; see Dropped.
+type NATSWriter struct {
+	cfg     NATSConfig
+	nc      *nats.Conn
+	js      jetstream.JetStream // nil if JetStream isn't available
+	entries chan babylogger.Entry
+	dropped uint64
+	pending int64 // entries queued or being published
+
+	mtx    sync.RWMutex // guards closed and sends on entries
+	closed bool
+
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// New connects to NATS and returns a NATSWriter. The connection reconnects
+// automatically for as long as the NATSWriter is open.
+func New(cfg NATSConfig) (*NATSWriter, error) {
+	if cfg.URL == "" {
+		cfg.URL = nats.DefaultURL
+	}
+	if cfg.Subject == "" {
+		cfg.Subject = DefaultSubject
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+
+	opts := append([]nats.Option{
+		nats.Name("babylogger"),
+		nats.MaxReconnects(-1),
+	}, cfg.Options...)
+	nc, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &NATSWriter{
+		cfg:     cfg,
+		nc:      nc,
+		entries: make(chan babylogger.Entry, cfg.BufferSize),
+	}
+	w.js = detectJetStream(nc, wildcard(cfg.Subject))
+
+	w.wg.Add(1)
+	go w.loop()
+	return w, nil
+}
+
+// detectJetStream returns a JetStream context if the server has JetStream
+// enabled and a stream covering subject.
+func detectJetStream(nc *nats.Conn, subject string) jetstream.JetStream {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := js.StreamNameBySubject(ctx, subject); err != nil {
+		return nil
+	}
+	return js
+}
+
+// WriteEntry queues an entry for publishing. It never blocks. Once the
+// writer is closed it returns an error instead.
+func (w *NATSWriter) WriteEntry(e babylogger.Entry) error {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	if w.closed {
+		return errors.New("babylogger/nats: write to closed NATSWriter")
+	}
+	if !w.nc.IsConnected() {
+		atomic.AddUint64(&w.dropped, 1)
+		return nil
+	}
+	atomic.AddInt64(&w.pending, 1)
+	select {
+	case w.entries <- e:
+	default:
+		atomic.AddInt64(&w.pending, -1)
+		atomic.AddUint64(&w.dropped, 1)
+	}
+	return nil
+}
+
+// Flush blocks until all queued entries have been published and
+// acknowledged by the server, or ctx is done.
+func (w *NATSWriter) Flush(ctx context.Context) error {
+	t := time.NewTicker(10 * time.Millisecond)
+	defer t.Stop()
+	for atomic.LoadInt64(&w.pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	if w.js != nil {
+		select {
+		case <-w.js.PublishAsyncComplete():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		return w.nc.Flush()
+	}
+	return w.nc.FlushWithContext(ctx)
+}
+
+// Dropped returns how many entries have been dropped because the connection
+// was unavailable or the queue was full.
+func (w *NATSWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Subscribe subscribes to the entries published by NATSWriters with the
+// same NATSConfig, as part of the configured queue group. Placeholders in
+// the subject are replaced with wildcards.
+func (w *NATSWriter) Subscribe(fn func(*nats.Msg)) (*nats.Subscription, error) {
+	return w.nc.QueueSubscribe(wildcard(w.cfg.Subject), w.cfg.QueueGroup, fn)
+}
+
+// Close publishes any queued entries and closes the connection. Entries
+// written after Close are rejected.
+func (w *NATSWriter) Close() error {
+	w.once.Do(func() {
+		w.mtx.Lock()
+		w.closed = true
+		close(w.entries)
+		w.mtx.Unlock()
+	})
+	w.wg.Wait()
+	if err := w.nc.Drain(); err != nil {
+		w.nc.Close()
+		return err
+	}
+	return nil
+}
+
+func (w *NATSWriter) loop() {
+	defer w.wg.Done()
+	for e := range w.entries {
+		if err := w.publish(e); err != nil {
+			atomic.AddUint64(&w.dropped, 1)
+		}
+		atomic.AddInt64(&w.pending, -1)
+	}
+}
+
+func (w *NATSWriter) publish(e babylogger.Entry) error {
+	b, err := json.Marshal(message(e))
+	if err != nil {
+		return err
+	}
+	subject := w.subject(e)
+	if w.js != nil {
+		_, err = w.js.PublishAsync(subject, b)
+		return err
+	}
+	return w.nc.Publish(subject, b)
+}
+
+// subject returns the subject to publish an entry on.
+func (w *NATSWriter) subject(e babylogger.Entry) string {
+	return strings.NewReplacer(
+		"{method}", token(e.Method),
+		"{status}", fmt.Sprint(e.Status),
+		"{status_class}", fmt.Sprintf("%dxx", e.Status/100),
+	).Replace(w.cfg.Subject)
+}
+
+// wildcard replaces the placeholders in a subject with wildcards.
+func wildcard(subject string) string {
+	return strings.NewReplacer(
+		"{method}", "*",
+		"{status}", "*",
+		"{status_class}", "*",
+	).Replace(subject)
+}
+
+// token makes s safe to use as a subject token.
+func token(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.NewReplacer(".", "_", " ", "_", "*", "_", ">", "_").Replace(s)
+}
+
+// msg is the JSON representation of an entry.
+type msg struct {
+	Time       time.Time              `json:"time"`
+	Method     string                 `json:"method"`
+	URI        string                 `json:"uri"`
+	Path       string                 `json:"path"`
+	Proto      string                 `json:"proto"`
+	RemoteAddr string                 `json:"remote_addr"`
+	Status     int                    `json:"status"`
+	Bytes      int                    `json:"bytes"`
+	DurationNS int64                  `json:"duration_ns"`
+	Attrs      map[string]interface{} `json:"attrs,omitempty"`
+}
+
+func message(e babylogger.Entry) msg {
+	m := msg{
+		Time:       e.Time,
+		Method:     e.Method,
+		URI:        e.URI,
+		Path:       e.Path,
+		Proto:      e.Proto,
+		RemoteAddr: e.RemoteAddr,
+		Status:     e.Status,
+		Bytes:      e.Bytes,
+		DurationNS: int64(e.Duration),
+	}
+	if len(e.Attrs) > 0 {
+		m.Attrs = make(map[string]interface{}, len(e.Attrs))
+		for _, a := range e.Attrs {
+			if d, ok := a.Value.(time.Duration); ok {
+				m.Attrs[a.Key] = d.String()
+				continue
+			}
+			m.Attrs[a.Key] = a.Value
+		}
+	}
+	return m
+}
@@ -0,0 +1,85 @@
+package babylogger
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// nginxTime is the layout of nginx's $time_local.
+const nginxTime = "02/Jan/2006:15:04:05 -0700"
+
+// nginxRequest holds the request details only the Nginx format logs.
+type nginxRequest struct {
+	userAgent string
+	referer   string
+}
+
+// logNginx logs an entry as a line in nginx's combined format.
+func (l *Logger) logNginx(e *Entry) {
+	var referer, userAgent string
+	if e.nginx != nil {
+		referer, userAgent = e.nginx.referer, e.nginx.userAgent
+	}
+
+	// nginx doesn't put brackets around IPv6 addresses
+	addr := strings.TrimSuffix(strings.TrimPrefix(e.RemoteAddr, "["), "]")
+
+	b := make([]byte, 0, 128+len(e.URI)+len(referer)+len(userAgent))
+	user := "-"
+	if v, ok := e.value("remote_user"); ok {
+		user, _ = v.(string)
+	}
+
+	b = append(b, nginxValue(addr)...)
+	b = append(b, " - "...)
+	b = appendNginxEscaped(b, nginxValue(user))
+	b = append(b, " ["...)
+	b = append(b, e.Time.Format(nginxTime)...)
+	b = append(b, `] "`...)
+	b = appendNginxEscaped(b, e.Method+" "+e.URI+" "+e.Proto)
+	b = append(b, `" `...)
+	b = strconv.AppendInt(b, int64(e.Status), 10)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(e.Bytes), 10)
+	b = append(b, ` "`...)
+	b = appendNginxEscaped(b, nginxValue(referer))
+	b = append(b, `" "`...)
+	b = appendNginxEscaped(b, nginxValue(userAgent))
+	b = append(b, "\"\n"...)
+
+	l.writeFor(e.Status, b)
+}
+
+// logNginxError logs a message in the format of nginx's error log.
+func (l *Logger) logNginxError(msg string) {
+	b := []byte(l.now().Format("2006/01/02 15:04:05") + " [error] ")
+	b = appendNginxEscaped(b, msg)
+	b = append(b, '\n')
+	l.writeFor(http.StatusInternalServerError, b)
+}
+
+// nginxValue returns v, or - if it's empty, like nginx renders missing
+// variables.
+func nginxValue(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+// appendNginxEscaped appends s to b escaped like nginx escapes variables in
+// its access log: double quotes, backslashes and bytes outside of printable
+// ASCII are written as \xHH.
+func appendNginxEscaped(b []byte, s string) []byte {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' || c == '\\' || c < 0x20 || c > 0x7e {
+			b = append(b, '\\', 'x', hex[c>>4], hex[c&0xf])
+			continue
+		}
+		b = append(b, c)
+	}
+	return b
+}
@@ -0,0 +1,146 @@
+// Package openapi checks responses against an OpenAPI 3 spec as they're
+// logged, turning the access log into a live contract test. It lives in its
+// own package so kin-openapi is only pulled in by programs that use it.
+//
+// With this package imported as babyopenapi:
+//
+//	spec, _ := os.ReadFile("openapi.yaml")
+//	l := babylogger.New(babyopenapi.WithOpenAPIValidation(spec))
+//
+// Responses that don't match the spec are logged like:
+//
+//	-> 200 OK 512B 1.2ms validation_error=true validation_detail="status 200 not defined for GET /users"
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/meowgorithm/babylogger"
+)
+
+// maxBodyBytes is how much of a response body is validated. The bodies of
+// longer responses aren't validated, only their statuses and content types.
+const maxBodyBytes = 1 << 20
+
+// WithOpenAPIValidation parses an OpenAPI 3 spec, in JSON or YAML, and
+// validates each response against the operation the request matched: its
+// status has to be one the operation defines, exactly or as a range like 2XX,
+// or the operation needs a default response, and its body has to match the
+// schema for its content type. Responses that don't pass are logged with
+// validation_error=true and what was wrong as validation_detail.
+//
+// Requests that don't match any operation in the spec aren't validated, and
+// neither are the bodies of responses over 1MB. It panics if the spec can't
+// be parsed or isn't valid, like babylogger.New does for invalid options.
+func WithOpenAPIValidation(spec []byte) babylogger.Option {
+	v, err := newValidator(spec)
+	if err != nil {
+		panic("babylogger: openapi: " + err.Error())
+	}
+	return babylogger.WithResponseBodyAttrs(maxBodyBytes, v.attrs)
+}
+
+// validator validates responses against a spec.
+type validator struct {
+	router routers.Router
+}
+
+func newValidator(spec []byte) (*validator, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &validator{router: router}, nil
+}
+
+// attrs returns the attributes for a response that doesn't match the spec.
+func (v *validator) attrs(r *http.Request, res babylogger.Response) []babylogger.Attr {
+	detail := v.validate(r, res)
+	if detail == "" {
+		return nil
+	}
+	return []babylogger.Attr{
+		{Key: "validation_error", Value: true},
+		{Key: "validation_detail", Value: detail},
+	}
+}
+
+// validate validates a response and returns what's wrong with it, if
+// anything.
+func (v *validator) validate(r *http.Request, res babylogger.Response) string {
+	route, params, err := v.router.FindRoute(r)
+	if err != nil {
+		return ""
+	}
+
+	if responses := route.Operation.Responses; len(responses) > 0 {
+		response := responseFor(responses, res.Status)
+		if response == nil {
+			return fmt.Sprintf("status %d not defined for %s %s", res.Status, r.Method, route.Path)
+		}
+		// openapi3filter only looks up exact statuses and default, so hand
+		// it an operation where the response we found is the exact one
+		op := *route.Operation
+		op.Responses = openapi3.Responses{strconv.Itoa(res.Status): response}
+		matched := *route
+		matched.Operation = &op
+		route = &matched
+	}
+
+	err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: params,
+			Route:      route,
+		},
+		Status: res.Status,
+		Header: res.Header,
+		Body:   io.NopCloser(bytes.NewReader(res.Body)),
+		Options: &openapi3filter.Options{
+			ExcludeResponseBody: res.Truncated,
+		},
+	})
+	if err == nil {
+		return ""
+	}
+
+	// Schema errors describe the whole schema; the path to the offending
+	// value and the reason are enough for a log line
+	var se *openapi3.SchemaError
+	if errors.As(err, &se) {
+		return fmt.Sprintf("response body for %s %s: %s: %s",
+			r.Method, route.Path, "/"+strings.Join(se.JSONPointer(), "/"), se.Reason)
+	}
+	return fmt.Sprintf("response for %s %s: %v", r.Method, route.Path, err)
+}
+
+// responseFor returns the response an operation defines for status: the one
+// for the exact status, or else the one for its range, like 4XX, or else the
+// default one.
+func responseFor(responses openapi3.Responses, status int) *openapi3.ResponseRef {
+	if response := responses.Get(status); response != nil {
+		return response
+	}
+	if response := responses[fmt.Sprintf("%dXX", status/100)]; response != nil {
+		return response
+	}
+	return responses.Default()
+}
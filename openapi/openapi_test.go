@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/meowgorithm/babylogger"
+)
+
+const spec = `
+openapi: 3.0.0
+info:
+  title: Users
+  version: "1"
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: The user
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string
+        "4XX":
+          description: A client error
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [error]
+                properties:
+                  error:
+                    type: string
+`
+
+func TestValidate(t *testing.T) {
+	v, err := newValidator([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	json := http.Header{"Content-Type": {"application/json"}}
+	for _, tt := range []struct {
+		name   string
+		status int
+		body   string
+		detail string
+	}{
+		{"matching", 200, `{"name":"kitty"}`, ""},
+		{"matching range", 404, `{"error":"not found"}`, ""},
+		{"undefined status", 500, `{}`, "status 500 not defined for GET /users/{id}"},
+		{"schema mismatch", 200, `{"name":42}`, "response body for GET /users/{id}: /name: "},
+		{"range schema mismatch", 404, `{}`, "response body for GET /users/{id}: /error: "},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/users/42", nil)
+			detail := v.validate(r, babylogger.Response{
+				Status: tt.status,
+				Header: json,
+				Body:   []byte(tt.body),
+			})
+			if tt.detail == "" && detail != "" || !strings.HasPrefix(detail, tt.detail) {
+				t.Errorf("got detail %q, want %q", detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestWithOpenAPIValidation(t *testing.T) {
+	var out bytes.Buffer
+	l := babylogger.New(
+		babylogger.WithOutput(&out),
+		babylogger.WithNoColor(),
+		WithOpenAPIValidation([]byte(spec)),
+	)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":42}`))
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	if !strings.Contains(out.String(), "validation_error=true") {
+		t.Errorf("invalid response not flagged: %q", out.String())
+	}
+
+	out.Reset()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/posts", nil))
+	if strings.Contains(out.String(), "validation_error") {
+		t.Errorf("request outside the spec flagged: %q", out.String())
+	}
+}
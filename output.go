@@ -0,0 +1,99 @@
+package babylogger
+
+import (
+	"bytes"
+	"io"
+	"log"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// WithOutput sets where log lines are written. By default they go through the
+// standard library's log package, so they end up wherever log.SetOutput
+// pointed it. Pretty lines written to w are prefixed with the date and time,
+// like the standard logger does, and rendered without colors unless w is a
+// terminal.
+func WithOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.out = w
+		l.logger = log.New(w, "", log.LstdFlags)
+	}
+}
+
+// WithNoColor disables colors, regardless of whether the output is a
+// terminal.
+func WithNoColor() Option {
+	return func(l *Logger) {
+		l.settings.noColor = true
+	}
+}
+
+// JSONBackend can be implemented by an output writer, like an adapter for a
+// structured logging library, to say it produces JSON. Babylogger never
+// renders colors to a writer that reports JSON output, so ANSI escape
+// sequences don't end up encoded in JSON strings. This applies to writers set
+// with WithOutput as well as to the standard logger's output.
+type JSONBackend interface {
+	IsJSONOutput() bool
+}
+
+// writer returns the writer log lines go to.
+func (l *Logger) writer() io.Writer {
+	if l.out != nil {
+		return l.out
+	}
+	return log.Writer()
+}
+
+// fileFlags are the log flags that add the caller's source file to lines.
+const fileFlags = log.Lshortfile | log.Llongfile
+
+// print logs a pretty line.
+//
+// Lines are logged from the goroutine serving the request, so the caller
+// the log package would report with log.Lshortfile or log.Llongfile is
+// always Babylogger itself, which is of no use to anyone. When those flags
+// are set the line is logged without the file instead.
+func (l *Logger) print(line string) {
+	logger := l.logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	if l.location != nil {
+		l.writeLine(l.logLine(logger, line))
+		return
+	}
+	if logger.Flags()&fileFlags != 0 {
+		printWithoutFile(logger, line)
+		return
+	}
+	logger.Print(line)
+}
+
+// printWithoutFile logs line to logger, without the file log flags.
+func printWithoutFile(logger *log.Logger, line string) {
+	var b bytes.Buffer
+	log.New(&b, logger.Prefix(), logger.Flags()&^fileFlags).Print(line)
+	logger.Writer().Write(b.Bytes())
+}
+
+// styles returns the theme to render lines with: the configured theme, or a
+// colorless version of it when colors are off or the output can't show
+// them.
+func (l *Logger) styles() *Theme {
+	if l.config().noColor || l.outColorless {
+		return &l.plain
+	}
+	if jb, ok := l.writer().(JSONBackend); ok && jb.IsJSONOutput() {
+		return &l.plain
+	}
+	return &l.theme
+}
+
+// plainTheme returns a copy of t that renders without colors.
+func plainTheme(t Theme) Theme {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.Ascii)
+	return t.renderer(r)
+}
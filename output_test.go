@@ -0,0 +1,50 @@
+package babylogger
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSourceFileLeftOut(t *testing.T) {
+	for _, flag := range []int{log.Lshortfile, log.Llongfile} {
+		out := new(syncBuffer)
+		prefix, flags, w := log.Prefix(), log.Flags(), log.Writer()
+		log.SetOutput(out)
+		log.SetPrefix("app: ")
+		log.SetFlags(log.LstdFlags | flag)
+
+		l := New(WithNoColor())
+		serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/source", nil))
+
+		log.SetOutput(w)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+
+		got := lines(out)
+		if len(got) != 2 {
+			t.Fatalf("flag %d: got %d lines, want 2:\n%s", flag, len(got), out.String())
+		}
+		for _, line := range got {
+			if strings.Contains(line, ".go:") {
+				t.Errorf("flag %d: line has a source file: %q", flag, line)
+			}
+			if !strings.HasPrefix(line, "app: ") {
+				t.Errorf("flag %d: line lost the log prefix: %q", flag, line)
+			}
+		}
+	}
+}
+
+func TestOutputColors(t *testing.T) {
+	theme := DefaultTheme().renderer(colorRenderer())
+	out := new(syncBuffer)
+	l := New(WithTheme(theme), WithOutput(out))
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+
+	if got := out.String(); strings.Contains(got, "\x1b[") {
+		t.Errorf("colors written to an output that isn't a terminal:\n%q", got)
+	}
+}
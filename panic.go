@@ -0,0 +1,178 @@
+package babylogger
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// panicFrames is how many stack frames WithPanicDetails logs.
+const panicFrames = 5
+
+// WithPanicRecovery recovers from panics in the next handler. The client gets
+// a 500 Internal Server Error if nothing has been written yet (see
+// WithPanicResponse), and the panic value is logged on the response line as
+// panic=<value>.
+//
+// Panics with http.ErrAbortHandler are logged and then re-panicked so the
+// server still aborts the response.
+func WithPanicRecovery() Option {
+	return func(l *Logger) {
+		l.panicRecovery = true
+	}
+}
+
+// WithPanicDetails logs the top stack frames of a recovered panic along with
+// its value. In the pretty format the frames are logged on their own lines
+// below the response line; in structured formats the panic is logged as an
+// object:
+//
+//	"panic": {"value": "index out of range", "frames": [...]}
+//
+// It implies WithPanicRecovery.
+func WithPanicDetails() Option {
+	return func(l *Logger) {
+		l.panicRecovery = true
+		l.panicDetails = true
+	}
+}
+
+// WithPanicResponse sets the response the client gets when a panic is
+// recovered, in place of the plain text 500 Internal Server Error, so it can
+// match the API's other error responses:
+//
+//	babylogger.WithPanicResponse(http.StatusInternalServerError,
+//		[]byte(`{"error":"internal"}`), "application/json")
+//
+// Like the default response, it's only written if the handler hadn't
+// written anything yet; the panic is logged either way. It implies
+// WithPanicRecovery.
+func WithPanicResponse(status int, body []byte, contentType string) Option {
+	return func(l *Logger) {
+		l.panicRecovery = true
+		l.panicResponse = &panicResponse{status: status, body: body, contentType: contentType}
+	}
+}
+
+// panicResponse is the response set with WithPanicResponse.
+type panicResponse struct {
+	status      int
+	body        []byte
+	contentType string
+}
+
+// writePanicResponse writes the response for a recovered panic.
+func (l *Logger) writePanicResponse(w http.ResponseWriter) {
+	p := l.panicResponse
+	if p == nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	if p.contentType != "" {
+		h.Set("Content-Type", p.contentType)
+	}
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.status)
+	w.Write(p.body)
+}
+
+// Panic describes a recovered panic. It's the value of the panic attribute
+// when WithPanicDetails is enabled.
+type Panic struct {
+	Value  string  `json:"value"`
+	Frames []Frame `json:"frames"`
+}
+
+// String returns the panic value.
+func (p Panic) String() string {
+	return p.Value
+}
+
+// Frame is a single stack frame.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String returns the frame formatted as function (file:line).
+func (f Frame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+}
+
+// serveRecover calls the next handler, recovering from any panic and adding
+// it to the entry. If the handler panicked with http.ErrAbortHandler it's
+// returned so the caller can re-panic once the request has been logged.
+func (l *Logger) serveRecover(w *logWriter, r *http.Request, next http.Handler, e *Entry) (abort interface{}) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+
+		if l.panicDetails {
+			e.add("panic", Panic{
+				Value:  fmt.Sprint(v),
+				Frames: parseStack(debug.Stack(), panicFrames),
+			})
+		} else {
+			e.add("panic", fmt.Sprint(v))
+		}
+
+		if v == http.ErrAbortHandler {
+			abort = v
+			return
+		}
+
+		if !w.headerWritten() {
+			l.writePanicResponse(w)
+		}
+	}()
+	next.ServeHTTP(w, r)
+	return nil
+}
+
+// parseStack parses the output of debug.Stack, called while panicking, and
+// returns up to n frames starting at the one that panicked.
+func parseStack(stack []byte, n int) []Frame {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "goroutine ") {
+		lines = lines[1:]
+	}
+
+	var frames []Frame
+	for i := 0; i+1 < len(lines); i += 2 {
+		fn := lines[i]
+		if paren := strings.LastIndex(fn, "("); paren > 0 {
+			fn = fn[:paren]
+		}
+
+		loc := strings.TrimSpace(lines[i+1])
+		if sp := strings.LastIndex(loc, " +0x"); sp > 0 {
+			loc = loc[:sp]
+		}
+		file, line := loc, 0
+		if colon := strings.LastIndex(loc, ":"); colon > 0 {
+			file = loc[:colon]
+			line, _ = strconv.Atoi(loc[colon+1:])
+		}
+
+		// Everything up to and including the call to panic is recovery
+		// machinery, so start over from the frame after it.
+		if fn == "panic" || fn == "runtime.gopanic" {
+			frames = frames[:0]
+			continue
+		}
+		frames = append(frames, Frame{Function: fn, File: file, Line: line})
+	}
+
+	if len(frames) > n {
+		frames = frames[:n]
+	}
+	return frames
+}
@@ -0,0 +1,70 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPanicResponse(t *testing.T) {
+	body := `{"error":"internal"}`
+	l, out := newTestLogger(WithPanicResponse(http.StatusServiceUnavailable, []byte(body), "application/json"))
+	w := serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		panic("boom")
+	}, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Body.String(); got != body {
+		t.Errorf("got body %q, want %q", got, body)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", got)
+	}
+	if got := w.Header().Get("Content-Length"); got != "" {
+		t.Errorf("handler's Content-Length %q left on the response", got)
+	}
+	if got := out.String(); !strings.Contains(got, "-> 503") || !strings.Contains(got, "panic=boom") {
+		t.Errorf("panic not logged:\n%s", got)
+	}
+}
+
+func TestPanicResponseHeadersSent(t *testing.T) {
+	l, out := newTestLogger(WithPanicResponse(http.StatusServiceUnavailable, []byte(`{"error":"internal"}`), "application/json"))
+	w := serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("partial"))
+		panic("boom")
+	}, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Body.String(); got != "partial" {
+		t.Errorf("got body %q, want the handler's only", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/plain" {
+		t.Errorf("got Content-Type %q, want text/plain", got)
+	}
+	if got := out.String(); !strings.Contains(got, "-> 200") || !strings.Contains(got, "panic=boom") {
+		t.Errorf("panic not logged:\n%s", got)
+	}
+}
+
+func TestPanicResponseDefault(t *testing.T) {
+	l, _ := newTestLogger(WithPanicRecovery())
+	w := serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if got := w.Body.String(); got != "Internal Server Error\n" {
+		t.Errorf("got body %q", got)
+	}
+}
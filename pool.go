@@ -0,0 +1,59 @@
+package babylogger
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// WithResponseWriterPool reuses the writers the middleware wraps around each
+// response, saving an allocation per request. It's worth it for services
+// that handle lots of small requests, where those allocations add up to
+// measurable GC pressure.
+//
+// A writer goes back to the pool once its request has been logged, so
+// handlers must not hold on to the http.ResponseWriter after they return,
+// which net/http doesn't allow anyway. Writers whose connection was hijacked,
+// or that were unwrapped with http.ResponseController, are never reused,
+// since something else may still hold them.
+func WithResponseWriterPool() Option {
+	return func(l *Logger) {
+		l.writerPool = true
+	}
+}
+
+var logWriterPool = sync.Pool{
+	New: func() interface{} {
+		return new(logWriter)
+	},
+}
+
+// newLogWriter returns a logWriter for w, from the pool if it's enabled.
+func (l *Logger) newLogWriter(w http.ResponseWriter) *logWriter {
+	if !l.writerPool {
+		return &logWriter{
+			ResponseWriter: w,
+			code:           http.StatusOK, // default. so important! see logWriter.
+		}
+	}
+	writer := logWriterPool.Get().(*logWriter)
+	*writer = logWriter{
+		ResponseWriter: w,
+		code:           http.StatusOK,
+	}
+	return writer
+}
+
+// releaseLogWriter returns a logWriter to the pool, if it's enabled and
+// nothing else could still be holding the writer.
+func (l *Logger) releaseLogWriter(w *logWriter) {
+	if !l.writerPool {
+		return
+	}
+	if atomic.LoadInt32(&w.hijack) == 1 || atomic.LoadInt32(&w.unwrapped) == 1 {
+		return
+	}
+	// Drop everything, so the pool doesn't keep the last response alive
+	*w = logWriter{}
+	logWriterPool.Put(w)
+}
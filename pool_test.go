@@ -0,0 +1,83 @@
+package babylogger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseWriterPoolNoStateBleed(t *testing.T) {
+	l, out := newTestLogger(WithResponseWriterPool())
+	dirty := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+		w.(http.Flusher).Flush()
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	clean := func(w http.ResponseWriter, r *http.Request) {}
+
+	for i := 0; i < 50; i++ {
+		serveTest(l, dirty, httptest.NewRequest("GET", "/dirty", nil))
+		w := serveTest(l, clean, httptest.NewRequest("GET", "/clean", nil))
+		if w.Code != http.StatusOK || w.Body.Len() != 0 {
+			t.Fatalf("clean request got %d with %d bytes", w.Code, w.Body.Len())
+		}
+	}
+
+	for _, line := range lines(out) {
+		if !strings.HasPrefix(line, "-> ") {
+			continue
+		}
+		if strings.Contains(line, "418") {
+			continue
+		}
+		if !strings.Contains(line, "-> 200 OK 0B") {
+			t.Fatalf("clean request logged with a previous request's state: %q", line)
+		}
+		if strings.Contains(line, "=") {
+			t.Fatalf("clean request logged with attributes: %q", line)
+		}
+	}
+}
+
+func TestReleaseLogWriterResets(t *testing.T) {
+	l := New(WithOutput(io.Discard), WithResponseWriterPool())
+	w := l.newLogWriter(httptest.NewRecorder())
+	w.WriteHeader(http.StatusTeapot)
+	w.Write([]byte("short and stout"))
+	l.releaseLogWriter(w)
+
+	if w.ResponseWriter != nil || w.code != 0 || w.bytes != 0 || w.headerWritten() {
+		t.Errorf("released writer kept its state: %+v", w)
+	}
+
+	w = l.newLogWriter(httptest.NewRecorder())
+	if w.code != http.StatusOK || w.bytes != 0 || w.headerWritten() {
+		t.Errorf("pooled writer isn't fresh: %+v", w)
+	}
+}
+
+func BenchmarkResponseWriterPool(b *testing.B) {
+	for _, pool := range []bool{false, true} {
+		name := "unpooled"
+		opts := []Option{WithOutput(io.Discard)}
+		if pool {
+			name = "pooled"
+			opts = append(opts, WithResponseWriterPool())
+		}
+		b.Run(name, func(b *testing.B) {
+			h := New(opts...).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			}))
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.ServeHTTP(w, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package babylogger
+
+import (
+	"context"
+	"log"
+)
+
+type loggerKey struct{}
+
+// WithRequestIDInLogPrefix gives every request its own *log.Logger, which
+// handlers get from the request's context with LoggerFromContext. It writes
+// where the standard logger does (or the output set with WithOutput), with
+// the request's ID in its prefix, right before the message. This way
+// anything handlers log can be matched with Babylogger's lines for the
+// request:
+//
+//	2009/11/10 23:00:00 <- GET /users/42 127.0.0.1 request_id=1k3XhbMz5aE
+//	2009/11/10 23:00:00 [1k3XhbMz5aE] loading user 42
+//	2009/11/10 23:00:00 -> 200 OK 512B 1.2ms
+//
+// Requests get IDs from WithRequestID or WithSnowflakeRequestID. If neither
+// is given, WithRequestID's UUIDs are used.
+func WithRequestIDInLogPrefix() Option {
+	return func(l *Logger) {
+		l.requestLogger = true
+		if l.requestID == nil {
+			l.requestID = newUUID
+		}
+	}
+}
+
+// LoggerFromContext returns the request's logger stored in ctx by
+// WithRequestIDInLogPrefix, or the standard logger if there isn't one. For
+// the slog.Logger stored by WithChildLogger, see SlogFromContext.
+func LoggerFromContext(ctx context.Context) *log.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*log.Logger); ok {
+		return logger
+	}
+	return log.Default()
+}
+
+// newRequestLogger returns a logger with id in its prefix.
+func (l *Logger) newRequestLogger(id string) *log.Logger {
+	base := l.logger
+	if base == nil {
+		base = log.Default()
+	}
+	return log.New(base.Writer(), base.Prefix()+"["+id+"] ", base.Flags()|log.Lmsgprefix)
+}
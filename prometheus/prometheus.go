@@ -0,0 +1,170 @@
+// Package prometheus exports Prometheus metrics for the requests Babylogger
+// logs. It lives in its own package so the Prometheus client is only pulled
+// in by programs that use it.
+//
+// Example:
+//
+//	c := prometheus.New(prometheus.WithMetricLabels(prometheus.LabelMethod, prometheus.LabelStatusClass))
+//	promclient.MustRegister(c)
+//
+//	l := babylogger.New(babylogger.WithEntryWriter(c))
+//	http.Handle("/metrics", promhttp.Handler())
+//	http.ListenAndServe(":8000", l.Middleware(mux))
+package prometheus
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/meowgorithm/babylogger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Label is a label requests can be broken down by.
+type Label string
+
+// Available labels.
+const (
+	// LabelMethod is the request method, e.g. GET. Methods other than the
+	// standard ones are counted as OTHER, so clients can't create new time
+	// series by sending made-up methods.
+	LabelMethod Label = "method"
+
+	// LabelRoute is the request's route, as logged by
+	// babylogger.WithStdRoutePattern or babylogger.WithPathTemplate, or its
+	// path if neither is used.
+	//
+	// Every distinct value is a new time series, so without one of those
+	// options a route label turns every URL ever requested, IDs and all,
+	// into its own series, which can quickly overwhelm Prometheus.
+	LabelRoute Label = "route"
+
+	// LabelStatusClass is the class of the response status, e.g. 2xx.
+	LabelStatusClass Label = "status_class"
+)
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithMetricLabels sets the labels requests are broken down by, in place of
+// the default, LabelStatusClass. Each label multiplies the number of time
+// series by the number of values it takes, so only add the labels you need.
+// With no labels, requests are only counted in total.
+func WithMetricLabels(labels ...Label) Option {
+	return func(c *Collector) {
+		c.labels = labels
+	}
+}
+
+// WithNamespace sets the namespace the metrics are in. The default is
+// babylogger.
+func WithNamespace(namespace string) Option {
+	return func(c *Collector) {
+		c.namespace = namespace
+	}
+}
+
+// Collector is a babylogger.EntryWriter that counts requests and records
+// their durations, and a prometheus.Collector exposing them as:
+//
+//	babylogger_http_requests_total            counter
+//	babylogger_http_request_duration_seconds  histogram
+//
+// both broken down by the labels set with WithMetricLabels.
+type Collector struct {
+	namespace string
+	labels    []Label
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// New returns a Collector. It must be registered with Prometheus and given
+// to babylogger.WithEntryWriter.
+func New(opts ...Option) *Collector {
+	c := &Collector{
+		namespace: "babylogger",
+		labels:    []Label{LabelStatusClass},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	names := make([]string, len(c.labels))
+	for i, l := range c.labels {
+		names[i] = string(l)
+	}
+	c.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests served.",
+	}, names)
+	c.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time spent serving HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, names)
+	return c
+}
+
+// WriteEntry implements babylogger.EntryWriter.
+func (c *Collector) WriteEntry(e babylogger.Entry) error {
+	values := make([]string, len(c.labels))
+	for i, l := range c.labels {
+		values[i] = labelValue(l, e)
+	}
+	c.requests.WithLabelValues(values...).Inc()
+	c.duration.WithLabelValues(values...).Observe(e.Duration.Seconds())
+	return nil
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+}
+
+// knownMethods are the methods that get a method label value of their own.
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// labelValue returns the value of a label for an entry.
+func labelValue(l Label, e babylogger.Entry) string {
+	switch l {
+	case LabelMethod:
+		if knownMethods[e.Method] {
+			return e.Method
+		}
+		return "OTHER"
+	case LabelRoute:
+		for _, a := range e.Attrs {
+			if a.Key == "route" {
+				if s, ok := a.Value.(string); ok {
+					return s
+				}
+			}
+		}
+		return e.Path
+	case LabelStatusClass:
+		return strconv.Itoa(e.Status/100) + "xx"
+	}
+	return ""
+}
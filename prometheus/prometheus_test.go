@@ -0,0 +1,76 @@
+package prometheus
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/meowgorithm/babylogger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// scrape returns the metrics exposed for reg.
+func scrape(t *testing.T, reg *prometheus.Registry) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	b, err := io.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestScrape(t *testing.T) {
+	c := New(WithMetricLabels(LabelMethod, LabelStatusClass))
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+
+	l := babylogger.New(babylogger.WithOutput(io.Discard), babylogger.WithEntryWriter(c))
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	requests := []struct{ method, path string }{
+		{"GET", "/"},
+		{"GET", "/"},
+		{"GET", "/missing"},
+		{"POST", "/"},
+		{"BREW", "/"},
+		{"get", "/"},
+	}
+	for _, r := range requests {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r.method, r.path, nil))
+	}
+
+	got := scrape(t, reg)
+	for _, want := range []string{
+		`babylogger_http_requests_total{method="GET",status_class="2xx"} 2`,
+		`babylogger_http_requests_total{method="GET",status_class="4xx"} 1`,
+		`babylogger_http_requests_total{method="POST",status_class="2xx"} 1`,
+		`babylogger_http_requests_total{method="OTHER",status_class="2xx"} 2`,
+		`babylogger_http_request_duration_seconds_count{method="GET",status_class="2xx"} 2`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("scrape is missing %s:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "BREW") {
+		t.Errorf("scrape has a label for an unknown method:\n%s", got)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	c := New(WithNamespace("app"), WithMetricLabels())
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+	c.WriteEntry(babylogger.Entry{Method: "GET", Status: 200})
+
+	if got := scrape(t, reg); !strings.Contains(got, "app_http_requests_total 1") {
+		t.Errorf("scrape is missing app_http_requests_total:\n%s", got)
+	}
+}
@@ -0,0 +1,125 @@
+package babylogger
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// WithTrustProxy resolves the client address, protocol and host from the
+// headers set by a reverse proxy, for servers running behind one. The client
+// address replaces the proxy's as the entry's RemoteAddr (which blocklists
+// and rate limits apply to too), and the protocol and host are logged as
+// forwarded_proto and forwarded_host.
+//
+// The standard Forwarded header (RFC 7239) is preferred:
+//
+//	Forwarded: for="[2001:db8::1]:4711";proto=https;host=example.com
+//
+// Without it, the X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host
+// headers are used. When a request went through several proxies the first
+// (leftmost) address is the client's.
+//
+// Clients can set these headers themselves, so only enable this when the
+// server can't be reached without going through a proxy that sets them.
+func WithTrustProxy() Option {
+	return func(l *Logger) {
+		l.trustProxy = true
+	}
+}
+
+// addForwarded resolves the client address, protocol and host from proxy
+// headers.
+func addForwarded(r *http.Request, e *Entry) {
+	var addr, proto, host string
+	if fwd := r.Header.Values("Forwarded"); len(fwd) > 0 {
+		addr, proto, host = parseForwarded(strings.Join(fwd, ","))
+	} else {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			addr = strings.TrimSpace(strings.Split(xff, ",")[0])
+		}
+		proto = r.Header.Get("X-Forwarded-Proto")
+		host = r.Header.Get("X-Forwarded-Host")
+	}
+
+	if ip := forwardedIP(addr); ip != "" {
+		e.RemoteAddr = ip
+	}
+	if proto != "" {
+		e.add("forwarded_proto", proto)
+	}
+	if host != "" {
+		e.add("forwarded_host", host)
+	}
+}
+
+// parseForwarded returns the for, proto and host parameters of the first
+// element of a Forwarded header.
+func parseForwarded(v string) (addr, proto, host string) {
+	elem, _ := splitQuoted(v, ',')
+	for elem != "" {
+		var pair string
+		pair, elem = splitQuoted(elem, ';')
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		value = unquote(strings.TrimSpace(value))
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "for":
+			addr = value
+		case "proto":
+			proto = value
+		case "host":
+			host = value
+		}
+	}
+	return addr, proto, host
+}
+
+// splitQuoted splits s at the first sep that isn't in a quoted string.
+func splitQuoted(s string, sep byte) (before, after string) {
+	var quoted bool
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			return s[:i], s[i+1:]
+		}
+	}
+	return s, ""
+}
+
+// unquote removes the quotes and escapes of a quoted string. Other values are
+// returned as is.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// forwardedIP returns the IP of a forwarded address, which may have a port
+// and IPv6 addresses may be in brackets, or an empty string if it isn't an IP,
+// like the "unknown" and obfuscated identifiers RFC 7239 allows.
+func forwardedIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
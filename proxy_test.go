@@ -0,0 +1,67 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseForwarded(t *testing.T) {
+	tests := []struct {
+		header            string
+		addr, proto, host string
+	}{
+		{`for=192.0.2.60;proto=http;host=example.com`, "192.0.2.60", "http", "example.com"},
+		{`for=192.0.2.43, for=198.51.100.17`, "192.0.2.43", "", ""},
+		{`for=192.0.2.43;proto=https, for=198.51.100.17;proto=http`, "192.0.2.43", "https", ""},
+		{`for="[2001:db8::1]:4711"`, "[2001:db8::1]:4711", "", ""},
+		{`For="[2001:db8::1]:4711";Proto=https, for=192.0.2.43`, "[2001:db8::1]:4711", "https", ""},
+		{`host="example.com;v=1";for=192.0.2.1`, "192.0.2.1", "", "example.com;v=1"},
+	}
+	for _, tt := range tests {
+		addr, proto, host := parseForwarded(tt.header)
+		if addr != tt.addr || proto != tt.proto || host != tt.host {
+			t.Errorf("parseForwarded(%q) = %q, %q, %q; want %q, %q, %q",
+				tt.header, addr, proto, host, tt.addr, tt.proto, tt.host)
+		}
+	}
+}
+
+func TestForwardedIP(t *testing.T) {
+	tests := map[string]string{
+		"192.0.2.60":         "192.0.2.60",
+		"192.0.2.60:8080":    "192.0.2.60",
+		"[2001:db8::1]:4711": "2001:db8::1",
+		"[2001:db8::1]":      "2001:db8::1",
+		"2001:db8::1":        "2001:db8::1",
+		"unknown":            "",
+		"_hidden":            "",
+	}
+	for addr, want := range tests {
+		if got := forwardedIP(addr); got != want {
+			t.Errorf("forwardedIP(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestTrustProxyPrefersForwarded(t *testing.T) {
+	var got Entry
+	l, _ := newTestLogger(WithTrustProxy(), WithEntryWriter(entryWriterFunc(func(e Entry) error {
+		got = e
+		return nil
+	})))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Add("Forwarded", `for="[2001:db8::1]:4711";proto=https`)
+	r.Header.Add("Forwarded", `for=198.51.100.17`)
+	r.Header.Set("X-Forwarded-For", "203.0.113.9")
+	r.Header.Set("X-Forwarded-Proto", "http")
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, r)
+
+	if got.RemoteAddr != "2001:db8::1" {
+		t.Errorf("RemoteAddr = %q, want 2001:db8::1", got.RemoteAddr)
+	}
+	if v, _ := got.value("forwarded_proto"); v != "https" {
+		t.Errorf("forwarded_proto = %v, want https", v)
+	}
+}
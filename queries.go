@@ -0,0 +1,66 @@
+package babylogger
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// QueryCounter counts database queries, for WithQueryCounter.
+type QueryCounter interface {
+	Count() int
+	Reset()
+}
+
+// WithQueryCounter logs the number of database queries each request made,
+// like db_queries=12. The counter is reset before the handler is called and
+// read after it returns, so it needs to count the queries of the request
+// being served: either the server handles one request at a time, or the
+// counter tells requests apart, e.g. by counting per goroutine like some
+// database library wrappers can. AtomicQueryCounter is a simple counter
+// handlers can increment themselves.
+func WithQueryCounter(counter QueryCounter) Option {
+	return func(l *Logger) {
+		l.queryCounter = counter
+	}
+}
+
+// WithQueryCountAlert calls fn when a request made more than max database
+// queries, as counted by the QueryCounter given to WithQueryCounter, with
+// the request and its count. It's a cheap way to catch N+1 query problems.
+// fn is called synchronously, before the response is logged.
+func WithQueryCountAlert(max int, fn func(r *http.Request, queries int)) Option {
+	return func(l *Logger) {
+		l.queryMax = max
+		l.queryAlert = fn
+	}
+}
+
+// AtomicQueryCounter is a QueryCounter that's safe for concurrent use.
+type AtomicQueryCounter struct {
+	n int64
+}
+
+// Inc counts a query.
+func (c *AtomicQueryCounter) Inc() {
+	atomic.AddInt64(&c.n, 1)
+}
+
+// Count implements QueryCounter.
+func (c *AtomicQueryCounter) Count() int {
+	return int(atomic.LoadInt64(&c.n))
+}
+
+// Reset implements QueryCounter.
+func (c *AtomicQueryCounter) Reset() {
+	atomic.StoreInt64(&c.n, 0)
+}
+
+// countQueries logs the number of queries a request made and calls the
+// alert if there were too many.
+func (l *Logger) countQueries(r *http.Request, e *Entry) {
+	n := l.queryCounter.Count()
+	e.add("db_queries", n)
+	if l.queryAlert != nil && n > l.queryMax {
+		l.queryAlert(r, n)
+	}
+}
@@ -0,0 +1,70 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAllowQuery(t *testing.T) {
+	allowed := map[string]bool{"q": true, "page": true, "a b": true}
+	tests := []struct {
+		uri, want string
+	}{
+		{"/search", "/search"},
+		{"/search?q=shoes", "/search?q=shoes"},
+		{"/search?token=s3cret", "/search"},
+		{"/search?token=s3cret&session=1", "/search"},
+		{"/search?q=shoes&token=s3cret&page=2", "/search?q=shoes&page=2"},
+		{"/search?q=shoes&token=s3cret&page=2&q=red", "/search?q=shoes&page=2&q=red"},
+		{"/search?page=2&q=shoes", "/search?page=2&q=shoes"},
+		{"/search?q", "/search?q"},
+		{"/search?q=&&page=2", "/search?q=&page=2"},
+		{"/search?a%20b=1&a+b=2&ab=3", "/search?a%20b=1&a+b=2"},
+		{"/search?Q=shoes&q=red", "/search?q=red"},
+	}
+	for _, tt := range tests {
+		if got := allowQuery(tt.uri, allowed); got != tt.want {
+			t.Errorf("allowQuery(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestQueryAllowlist(t *testing.T) {
+	l, out := newTestLogger(WithQueryAllowlist("q"))
+	var uri string
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		uri = r.RequestURI
+	}, httptest.NewRequest("GET", "/search?q=shoes&token=s3cret&q=red", nil))
+
+	got := out.String()
+	if !strings.Contains(got, "GET /search?q=shoes&q=red ") {
+		t.Errorf("query not filtered:\n%s", got)
+	}
+	if strings.Contains(got, "s3cret") {
+		t.Errorf("disallowed parameter logged:\n%s", got)
+	}
+	if uri != "/search?q=shoes&token=s3cret&q=red" {
+		t.Errorf("request URI changed to %q", uri)
+	}
+}
+
+func TestQueryAllowlistMiddlewarePanic(t *testing.T) {
+	l, out := newTestLogger(WithQueryAllowlist("q"))
+	bad := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("nil map")
+		})
+	}
+	h := l.SafeChain(bad)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/search?q=shoes&token=s3cret", nil))
+
+	got := out.String()
+	if !strings.Contains(got, "middleware_panic") {
+		t.Fatalf("middleware panic not logged:\n%s", got)
+	}
+	if strings.Contains(got, "s3cret") {
+		t.Errorf("disallowed parameter logged:\n%s", got)
+	}
+}
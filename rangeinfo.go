@@ -0,0 +1,30 @@
+package babylogger
+
+import "net/http"
+
+// WithRangeInfo logs which part of a resource was served for 206 Partial
+// Content responses: the Range the client asked for, and the Content-Range
+// the handler answered with.
+//
+//	-> 206 Partial Content 100B 3.6ms range="bytes=0-99" content_range="bytes 0-99/1000"
+//
+// It's handy for debugging seeking and streaming in media servers, where the
+// byte count alone doesn't say which range was sent.
+func WithRangeInfo() Option {
+	return func(l *Logger) {
+		l.rangeInfo = true
+	}
+}
+
+// addRange logs the requested and served ranges of a partial response.
+func addRange(r *http.Request, w *logWriter, e *Entry) {
+	if w.code != http.StatusPartialContent {
+		return
+	}
+	if v := r.Header.Get("Range"); v != "" {
+		e.add("range", v)
+	}
+	if v := w.Header().Get("Content-Range"); v != "" {
+		e.add("content_range", v)
+	}
+}
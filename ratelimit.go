@@ -0,0 +1,102 @@
+package babylogger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Rate limited responses (429 Too Many Requests) are highlighted by default:
+// they're rendered in their own amber style, and the Retry-After response
+// header is logged when the handler sets it.
+
+// WithNoRateLimitHighlight disables the special handling of 429 responses so
+// they're logged like any other 4xx.
+func WithNoRateLimitHighlight() Option {
+	return func(l *Logger) {
+		l.noRateLimitHighlight = true
+	}
+}
+
+// rateLimited reports whether a status code should be treated as rate
+// limited.
+func (l *Logger) rateLimited(code int) bool {
+	return code == http.StatusTooManyRequests && !l.noRateLimitHighlight
+}
+
+// RateLimiter enforces a request rate limit per client. See the redis
+// sub-package for a Redis-backed implementation.
+type RateLimiter interface {
+	// Allow records a request from the client with the given key (its IP
+	// address) and reports whether it's within the limit.
+	Allow(ctx context.Context, key string) (RateLimit, error)
+}
+
+// RateLimit is the outcome of a RateLimiter check.
+type RateLimit struct {
+	Allowed    bool
+	Limit      int           // requests allowed per Window
+	Window     time.Duration // the window Limit applies to
+	Remaining  int           // requests left in the current window
+	RetryAfter time.Duration // when the client may retry, if not allowed
+}
+
+// String returns the limit formatted as requests per window, e.g. 100/min.
+func (r RateLimit) String() string {
+	switch r.Window {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.Limit)
+	case time.Minute:
+		return fmt.Sprintf("%d/min", r.Limit)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.Limit)
+	}
+	return fmt.Sprintf("%d/%s", r.Limit, r.Window)
+}
+
+// WithRateLimiter enforces a rate limit per client IP. Requests over the
+// limit get a 429 Too Many Requests response with a Retry-After header and
+// don't reach the next handler. They're logged like:
+//
+//	rate_limited=true ip=1.2.3.4 limit=100/min remaining=0 retry_after=12s
+//
+// Allowed requests are logged with the number of requests the client has
+// left, e.g. rate_remaining=42. If the limiter returns an error the request
+// is allowed through and the error is logged as rate_limit_error.
+func WithRateLimiter(rl RateLimiter) Option {
+	return func(l *Logger) {
+		l.rateLimiter = rl
+	}
+}
+
+// checkRateLimit runs the rate limiter, if any, and reports whether the
+// request should be rejected.
+func (l *Logger) checkRateLimit(r *http.Request, e *Entry) bool {
+	if l.rateLimiter == nil || r == nil {
+		return false
+	}
+
+	rl, err := l.rateLimiter.Allow(r.Context(), e.RemoteAddr)
+	if err != nil {
+		e.add("rate_limit_error", err.Error())
+		return false
+	}
+	if rl.Allowed {
+		e.add("rate_remaining", rl.Remaining)
+		return false
+	}
+
+	retry := rl.RetryAfter.Round(time.Second)
+	if retry < time.Second {
+		retry = time.Second
+	}
+	e.add("rate_limited", true)
+	e.add("ip", e.RemoteAddr)
+	e.add("limit", rl.String())
+	e.add("remaining", rl.Remaining)
+	e.add("retry_after", retry)
+	e.retryAfter = strconv.Itoa(int(retry / time.Second))
+	return true
+}
@@ -0,0 +1,197 @@
+package babylogger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// reconnectQueueSize is how many writes a ReconnectingWriter holds on to
+// while it's disconnected.
+const reconnectQueueSize = 1024
+
+// ReconnectStats are a ReconnectingWriter's counters.
+type ReconnectStats struct {
+	// Reconnections is how many times the connection was dialed again after
+	// being lost.
+	Reconnections uint64
+
+	// DroppedEntries is how many writes were dropped because the queue was
+	// full while disconnected.
+	DroppedEntries uint64
+}
+
+// ReconnectingWriter is an output for network log destinations, like syslog
+// or a Logstash TCP input, that survives dropped connections. Give it to
+// WithOutput:
+//
+//	w := babylogger.NewReconnectingWriter(func() (io.WriteCloser, error) {
+//		return net.Dial("tcp", "logstash:5000")
+//	}, time.Second)
+//	defer w.Close()
+//
+//	l := babylogger.New(babylogger.WithOutput(w), babylogger.WithFormat(babylogger.JSON))
+//
+// When a write fails the connection is closed and dialed again every retry
+// interval until that succeeds. Meanwhile, writes are queued, up to 1024 of
+// them, and written once reconnected; past that the oldest are dropped and
+// counted in Stats. Writes never fail while the writer is open.
+type ReconnectingWriter struct {
+	dial  func() (io.WriteCloser, error)
+	retry time.Duration
+
+	mtx       sync.Mutex
+	conn      io.WriteCloser
+	queue     [][]byte // a ring buffer of reconnectQueueSize writes
+	start     int
+	queued    int
+	dialing   bool
+	connected bool // whether the writer has ever been connected
+	closed    bool
+	stats     ReconnectStats
+	closeCh   chan struct{}
+	dialingWG sync.WaitGroup
+}
+
+// NewReconnectingWriter returns a ReconnectingWriter that connects with dial,
+// retrying every retryInterval when dial fails. It dials right away, in the
+// background.
+func NewReconnectingWriter(dial func() (io.WriteCloser, error), retryInterval time.Duration) *ReconnectingWriter {
+	if retryInterval <= 0 {
+		retryInterval = time.Second
+	}
+	w := &ReconnectingWriter{
+		dial:    dial,
+		retry:   retryInterval,
+		queue:   make([][]byte, reconnectQueueSize),
+		closeCh: make(chan struct{}),
+	}
+	w.mtx.Lock()
+	w.redial(false)
+	w.mtx.Unlock()
+	return w
+}
+
+// Write writes p to the connection, or queues it if there's no connection.
+func (w *ReconnectingWriter) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.closed {
+		return 0, errors.New("babylogger: write to closed ReconnectingWriter")
+	}
+
+	if w.conn != nil {
+		if _, err := w.conn.Write(p); err == nil {
+			return len(p), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+		w.redial(true)
+	}
+	w.enqueue(p)
+	return len(p), nil
+}
+
+// Stats returns the writer's counters.
+func (w *ReconnectingWriter) Stats() ReconnectStats {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.stats
+}
+
+// Close closes the connection and stops reconnecting. Writes still queued
+// are dropped.
+func (w *ReconnectingWriter) Close() error {
+	w.mtx.Lock()
+	if w.closed {
+		w.mtx.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.closeCh)
+	conn := w.conn
+	w.conn = nil
+	w.mtx.Unlock()
+
+	w.dialingWG.Wait()
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// enqueue queues a copy of p, dropping the oldest write if the queue is
+// full. w.mtx must be held.
+func (w *ReconnectingWriter) enqueue(p []byte) {
+	if w.queued == len(w.queue) {
+		w.queue[w.start] = nil
+		w.start = (w.start + 1) % len(w.queue)
+		w.queued--
+		w.stats.DroppedEntries++
+	}
+	w.queue[(w.start+w.queued)%len(w.queue)] = append([]byte(nil), p...)
+	w.queued++
+}
+
+// redial starts dialing in the background, unless that's already happening.
+// If wait is set, the first attempt is after the retry interval. w.mtx must
+// be held.
+func (w *ReconnectingWriter) redial(wait bool) {
+	if w.dialing || w.closed {
+		return
+	}
+	w.dialing = true
+	w.dialingWG.Add(1)
+	go func() {
+		defer w.dialingWG.Done()
+		for {
+			if wait {
+				select {
+				case <-time.After(w.retry):
+				case <-w.closeCh:
+					return
+				}
+			}
+			wait = true
+
+			conn, err := w.dial()
+			if err != nil {
+				continue
+			}
+			if w.connect(conn) {
+				return
+			}
+		}
+	}()
+}
+
+// connect starts using conn, writing out the queue first. It reports whether
+// the writer is now connected, or closed; if writing the queue fails the
+// connection is dropped and the rest of the queue kept.
+func (w *ReconnectingWriter) connect(conn io.WriteCloser) bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.closed {
+		conn.Close()
+		return true
+	}
+
+	for w.queued > 0 {
+		if _, err := conn.Write(w.queue[w.start]); err != nil {
+			conn.Close()
+			return false
+		}
+		w.queue[w.start] = nil
+		w.start = (w.start + 1) % len(w.queue)
+		w.queued--
+	}
+
+	if w.connected {
+		w.stats.Reconnections++
+	}
+	w.connected = true
+	w.conn = conn
+	w.dialing = false
+	return true
+}
@@ -0,0 +1,94 @@
+// Package redis provides a Redis-backed rate limiter and replay store for
+// Babylogger. It lives in its own package so the Redis client is only pulled
+// in by programs that use it.
+//
+// Example:
+//
+//	rdb := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+//	l := babylogger.New(redis.WithRateLimit(rdb, 100))
+//	http.ListenAndServe(":8000", l.Middleware(mux))
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/meowgorithm/babylogger"
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyPrefix is prepended to the client IP to form the Redis key for its
+// request window.
+const KeyPrefix = "babylogger:ratelimit:"
+
+// WithRateLimit limits every client IP to requestsPerMinute requests in any
+// sliding one minute window, tracked in Redis so the limit is shared by all
+// instances of a service. See babylogger.WithRateLimiter for how rate limited
+// requests are handled and logged.
+func WithRateLimit(client *redis.Client, requestsPerMinute int) babylogger.Option {
+	return babylogger.WithRateLimiter(NewRateLimiter(client, requestsPerMinute, time.Minute))
+}
+
+// RateLimiter is a sliding window rate limiter backed by Redis sorted sets.
+// Each client gets a sorted set of request timestamps; timestamps older than
+// the window are trimmed on every request and the remainder are counted.
+type RateLimiter struct {
+	client *redis.Client
+	limit  int
+	window time.Duration
+}
+
+// NewRateLimiter returns a RateLimiter allowing limit requests per window.
+func NewRateLimiter(client *redis.Client, limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{client: client, limit: limit, window: window}
+}
+
+// slidingWindow trims, counts and, if there's room, records a request
+// atomically. It returns {allowed, remaining, retry after in ms}.
+var slidingWindow = redis.NewScript(`
+local key    = KEYS[1]
+local now    = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit  = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+
+local retry = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+end
+return {0, 0, retry}
+`)
+
+// Allow implements babylogger.RateLimiter.
+func (rl *RateLimiter) Allow(ctx context.Context, key string) (babylogger.RateLimit, error) {
+	res := babylogger.RateLimit{Limit: rl.limit, Window: rl.window}
+
+	// Members need to be unique so requests in the same millisecond are
+	// counted separately
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return res, err
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	vals, err := slidingWindow.Run(ctx, rl.client, []string{KeyPrefix + key},
+		now, rl.window.Milliseconds(), rl.limit, hex.EncodeToString(id[:])).Int64Slice()
+	if err != nil {
+		return res, err
+	}
+
+	res.Allowed = vals[0] == 1
+	res.Remaining = int(vals[1])
+	res.RetryAfter = time.Duration(vals[2]) * time.Millisecond
+	return res, nil
+}
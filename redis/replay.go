@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReplayKeyPrefix is prepended to request fingerprints to form their Redis
+// keys.
+const ReplayKeyPrefix = "babylogger:replay:"
+
+// replayTimeout bounds each Redis call made by a ReplayStore, since the
+// babylogger.ReplayStore interface doesn't take a context.
+const replayTimeout = time.Second
+
+// ReplayStore is a babylogger.ReplayStore backed by Redis, so replays are
+// detected across all instances of a service. Fingerprints are stored as keys
+// that expire on their own.
+//
+// If Redis can't be reached requests are let through: HasSeen reports false
+// and Mark does nothing.
+type ReplayStore struct {
+	client *redis.Client
+}
+
+// NewReplayStore returns a ReplayStore using the given client.
+//
+//	l := babylogger.New(
+//		babylogger.WithReplayDetection(10*time.Minute, redis.NewReplayStore(rdb)),
+//	)
+func NewReplayStore(client *redis.Client) *ReplayStore {
+	return &ReplayStore{client: client}
+}
+
+// HasSeen implements babylogger.ReplayStore.
+func (s *ReplayStore) HasSeen(fingerprint string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+	defer cancel()
+	n, err := s.client.Exists(ctx, ReplayKeyPrefix+fingerprint).Result()
+	return err == nil && n > 0
+}
+
+// Mark implements babylogger.ReplayStore.
+func (s *ReplayStore) Mark(fingerprint string, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+	defer cancel()
+	s.client.Set(ctx, ReplayKeyPrefix+fingerprint, 1, ttl)
+}
@@ -0,0 +1,35 @@
+package babylogger
+
+import "net/http"
+
+// WithRemoteUser logs the username of requests using HTTP Basic
+// Authentication as remote_user, or remote_user=- for requests without
+// credentials or with a malformed Authorization header. The password is
+// never logged.
+//
+// The Nginx format and the compat package's CLF writer log it in place of
+// $remote_user, which they otherwise render as -.
+func WithRemoteUser() Option {
+	return func(l *Logger) {
+		l.remoteUser = true
+	}
+}
+
+// addRemoteUser logs the request's Basic Auth username.
+func addRemoteUser(r *http.Request, e *Entry) {
+	user, _, ok := r.BasicAuth()
+	if !ok || user == "" {
+		user = "-"
+	}
+	e.add("remote_user", user)
+}
+
+// value returns the value of the entry's attribute with the given key.
+func (e *Entry) value(key string) (interface{}, bool) {
+	for _, a := range e.Attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,141 @@
+package babylogger
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReplayStore remembers request fingerprints for WithReplayDetection.
+type ReplayStore interface {
+	// HasSeen reports whether fingerprint has been marked and hasn't expired.
+	HasSeen(fingerprint string) bool
+
+	// Mark remembers fingerprint for ttl.
+	Mark(fingerprint string, ttl time.Duration)
+}
+
+// WithReplayDetection rejects requests that repeat one seen within window,
+// which can be a replay attack or a client retrying a request that must only
+// be processed once. Repeated requests get a 409 Conflict without reaching
+// the handler and are logged with replay_detected=true.
+//
+// Requests are identified by their method, path and X-Idempotency-Key
+// header. Requests without that header aren't checked; see
+// WithReplayFingerprint to identify them differently.
+//
+// Checking and marking a fingerprint are separate calls, so two identical
+// requests arriving at the same time may both get through. Use
+// NewInMemoryReplayStore for a single server, or a shared store, like the one
+// in the redis package, for several.
+func WithReplayDetection(window time.Duration, store ReplayStore) Option {
+	return func(l *Logger) {
+		l.replayWindow = window
+		l.replayStore = store
+		if l.replayFingerprint == nil {
+			l.replayFingerprint = idempotencyFingerprint
+		}
+	}
+}
+
+// WithReplayFingerprint sets how WithReplayDetection identifies requests.
+// Requests for which fn returns an empty string aren't checked.
+func WithReplayFingerprint(fn func(*http.Request) string) Option {
+	return func(l *Logger) {
+		l.replayFingerprint = fn
+	}
+}
+
+// idempotencyFingerprint identifies requests by their method, path and
+// idempotency key.
+func idempotencyFingerprint(r *http.Request) string {
+	key := r.Header.Get("X-Idempotency-Key")
+	if key == "" {
+		return ""
+	}
+	return r.Method + " " + r.URL.Path + " " + key
+}
+
+// checkReplay reports whether a request is a replay, and otherwise marks it
+// as seen.
+func (l *Logger) checkReplay(r *http.Request, e *Entry) bool {
+	fp := l.replayFingerprint(r)
+	if fp == "" {
+		return false
+	}
+	if l.replayStore.HasSeen(fp) {
+		e.add("replay_detected", true)
+		return true
+	}
+	l.replayStore.Mark(fp, l.replayWindow)
+	return false
+}
+
+// InMemoryReplayStore is a ReplayStore that keeps fingerprints in memory.
+// When it's full, the least recently marked fingerprints are evicted first.
+type InMemoryReplayStore struct {
+	mtx     sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // front is the most recently marked
+}
+
+type replayEntry struct {
+	fingerprint string
+	expires     time.Time
+}
+
+// defaultReplayStoreSize is how many fingerprints an InMemoryReplayStore
+// holds when it's given no size.
+const defaultReplayStoreSize = 10000
+
+// NewInMemoryReplayStore returns an InMemoryReplayStore that holds up to
+// size fingerprints, or 10,000 if size isn't positive.
+func NewInMemoryReplayStore(size int) *InMemoryReplayStore {
+	if size <= 0 {
+		size = defaultReplayStoreSize
+	}
+	return &InMemoryReplayStore{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// HasSeen implements ReplayStore.
+func (s *InMemoryReplayStore) HasSeen(fingerprint string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	el, ok := s.entries[fingerprint]
+	if !ok {
+		return false
+	}
+	if time.Now().After(el.Value.(*replayEntry).expires) {
+		s.order.Remove(el)
+		delete(s.entries, fingerprint)
+		return false
+	}
+	return true
+}
+
+// Mark implements ReplayStore.
+func (s *InMemoryReplayStore) Mark(fingerprint string, ttl time.Duration) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if el, ok := s.entries[fingerprint]; ok {
+		el.Value.(*replayEntry).expires = expires
+		s.order.MoveToFront(el)
+		return
+	}
+
+	s.entries[fingerprint] = s.order.PushFront(&replayEntry{fingerprint, expires})
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*replayEntry).fingerprint)
+	}
+}
@@ -0,0 +1,29 @@
+package babylogger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestInMemoryReplayStoreSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		s := NewInMemoryReplayStore(size)
+		for i := 0; i < 3; i++ {
+			s.Mark(fmt.Sprint(i), time.Minute)
+		}
+		for i := 0; i < 3; i++ {
+			if !s.HasSeen(fmt.Sprint(i)) {
+				t.Errorf("size %d: fingerprint %d forgotten", size, i)
+			}
+		}
+	}
+
+	s := NewInMemoryReplayStore(2)
+	for i := 0; i < 3; i++ {
+		s.Mark(fmt.Sprint(i), time.Minute)
+	}
+	if s.HasSeen("0") || !s.HasSeen("1") || !s.HasSeen("2") {
+		t.Error("least recently marked fingerprint not evicted")
+	}
+}
@@ -0,0 +1,46 @@
+package babylogger
+
+import (
+	"context"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID gives every request an ID, logged as request_id. The ID is
+// taken from the request's X-Request-ID header when there is one, so IDs
+// assigned by a proxy are kept, and is otherwise a new random UUID. It's set
+// as the response's X-Request-ID header and stored in the request's context,
+// where handlers can get it with RequestIDFromContext.
+func WithRequestID() Option {
+	return func(l *Logger) {
+		l.requestID = newUUID
+		l.snowflake = nil
+	}
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by WithRequestID
+// or WithSnowflakeRequestID, or an empty string if there isn't one.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// addRequestID reads or generates the request's ID, sets it as the response
+// header and returns the request with the ID in its context.
+func (l *Logger) addRequestID(w http.ResponseWriter, r *http.Request, e *Entry) *http.Request {
+	id := r.Header.Get("X-Request-ID")
+	if id == "" {
+		id = l.requestID()
+	}
+	w.Header().Set("X-Request-ID", id)
+	e.add("request_id", id)
+
+	ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+	if l.requestLogger {
+		ctx = context.WithValue(ctx, loggerKey{}, l.newRequestLogger(id))
+	}
+	r = r.WithContext(ctx)
+	e.ctx = ctx
+	return r
+}
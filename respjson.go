@@ -0,0 +1,106 @@
+package babylogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"strings"
+)
+
+// defaultResponseJSONMaxBytes is how much of a response body
+// WithResponseJSONFields reads by default.
+const defaultResponseJSONMaxBytes = 64 << 10 // 64 KiB
+
+// WithResponseJSONFields logs fields of JSON error responses. For responses
+// with a 4xx or 5xx status and a JSON content type, the given top-level
+// fields are read from the body and logged with a resp_ prefix, like:
+//
+//	resp_error="invalid token" resp_code=1042
+//
+// Only string and number fields are logged. The body is copied as it's
+// written, up to 64 KiB by default (see WithResponseJSONMaxBytes), and fields
+// past that are missed.
+func WithResponseJSONFields(fields ...string) Option {
+	return func(l *Logger) {
+		l.respJSONFields = fields
+		if l.respJSONMaxBytes == 0 {
+			l.respJSONMaxBytes = defaultResponseJSONMaxBytes
+		}
+	}
+}
+
+// WithResponseJSONMaxBytes sets how much of a response body
+// WithResponseJSONFields reads.
+func WithResponseJSONMaxBytes(n int) Option {
+	return func(l *Logger) {
+		l.respJSONMaxBytes = n
+	}
+}
+
+// bodyCapture copies the start of a response body.
+type bodyCapture struct {
+	max     int
+	decided bool // whether the response has been checked
+	buf     *bytes.Buffer
+}
+
+// write copies p, if the response is one to capture.
+func (c *bodyCapture) write(w *logWriter, p []byte) {
+	if !c.decided {
+		c.decided = true
+		if w.code >= 400 && isJSON(w.Header().Get("Content-Type")) {
+			c.buf = new(bytes.Buffer)
+		}
+	}
+	if c.buf == nil {
+		return
+	}
+	if room := c.max - c.buf.Len(); room > 0 {
+		if len(p) > room {
+			p = p[:room]
+		}
+		c.buf.Write(p)
+	}
+}
+
+// isJSON reports whether a content type is JSON, like application/json or
+// application/problem+json.
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json"))
+}
+
+// addResponseJSONFields adds the wanted fields of a captured JSON body to an
+// entry. The body may be truncated, in which case the fields read before the
+// cut are added.
+func (l *Logger) addResponseJSONFields(body []byte, e *Entry) {
+	want := make(map[string]bool, len(l.respJSONFields))
+	for _, f := range l.respJSONFields {
+		want[f] = true
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return
+		}
+		key, _ := t.(string)
+
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return
+		}
+		if !want[key] {
+			continue
+		}
+		switch v.(type) {
+		case string, json.Number:
+			e.add("resp_"+key, v)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package babylogger
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WithStdRoutePattern logs the standard library ServeMux pattern that
+// matched each request as route, like route="GET /users/{id}". Logs can
+// then be grouped by route rather than by URI without a third-party router.
+// When no pattern matched, e.g. because the handler isn't a ServeMux, the
+// URI is logged as the route instead, or the templated path if
+// WithPathTemplate is used too.
+func WithStdRoutePattern() Option {
+	return func(l *Logger) {
+		l.stdRoutePattern = true
+	}
+}
+
+// WithPathTemplate logs the route of each request as the result of fn
+// applied to its path. It's meant to collapse IDs and other parts of paths
+// that vary from request to request, so /users/12345 and /users/67890 can be
+// grouped as /users/{id}. The path itself is still logged as is. When fn is
+// nil, TemplatePath is used.
+func WithPathTemplate(fn func(string) string) Option {
+	if fn == nil {
+		fn = TemplatePath
+	}
+	return func(l *Logger) {
+		l.pathTemplate = fn
+	}
+}
+
+// TemplatePath replaces the path segments that look like IDs with
+// placeholders: numbers become {id} and UUIDs become {uuid}. For example,
+// /users/42/keys/6ba7b810-9dad-11d1-80b4-00c04fd430c8 becomes
+// /users/{id}/keys/{uuid}.
+func TemplatePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		switch {
+		case isNumber(s):
+			segments[i] = "{id}"
+		case isUUID(s):
+			segments[i] = "{uuid}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// WithNormalizeTrailingSlash strips the trailing slash from logged routes,
+// except for the root, /, so /users and /users/ are grouped together. The
+// path itself is still logged as is. If routes aren't logged otherwise, with
+// WithStdRoutePattern or WithPathTemplate, the request's path is logged as
+// its route.
+func WithNormalizeTrailingSlash() Option {
+	return func(l *Logger) {
+		l.trimSlash = true
+	}
+}
+
+// logsRoute reports whether requests' routes are logged.
+func (l *Logger) logsRoute() bool {
+	return l.stdRoutePattern || l.pathTemplate != nil || l.trimSlash
+}
+
+// route returns the route a request matched.
+func (l *Logger) route(r *http.Request, e *Entry) string {
+	route := e.URI
+	switch {
+	case l.stdRoutePattern && r.Pattern != "":
+		route = r.Pattern
+	case l.pathTemplate != nil:
+		route = l.pathTemplate(e.Path)
+	case !l.stdRoutePattern:
+		route = e.Path
+	}
+	if l.trimSlash {
+		route = trimTrailingSlash(route)
+	}
+	return route
+}
+
+// trimTrailingSlash strips the trailing slash from the path in a route,
+// which may be preceded by a method and followed by a query, unless the path
+// is the root.
+func trimTrailingSlash(route string) string {
+	route, query, hasQuery := strings.Cut(route, "?")
+	if trimmed := strings.TrimSuffix(route, "/"); trimmed != "" && !strings.HasSuffix(trimmed, " ") {
+		route = trimmed
+	}
+	if hasQuery {
+		route += "?" + query
+	}
+	return route
+}
@@ -0,0 +1,83 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// routeOf serves r with l and returns the logged route.
+func routeOf(t *testing.T, l *Logger, r *http.Request) interface{} {
+	t.Helper()
+	var route interface{}
+	l.entryWriters = append(l.entryWriters, entryWriterFunc(func(e Entry) error {
+		route, _ = e.value("route")
+		return nil
+	}))
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, r)
+	return route
+}
+
+func TestTemplatePath(t *testing.T) {
+	tests := map[string]string{
+		// Numeric
+		"/users/12345":    "/users/{id}",
+		"/users/0/orders": "/users/{id}/orders",
+		"/1/2/3":          "/{id}/{id}/{id}",
+
+		// UUID
+		"/keys/6ba7b810-9dad-11d1-80b4-00c04fd430c8":      "/keys/{uuid}",
+		"/keys/6BA7B810-9DAD-11D1-80B4-00C04FD430C8/info": "/keys/{uuid}/info",
+
+		// Mixed
+		"/users/42/keys/6ba7b810-9dad-11d1-80b4-00c04fd430c8": "/users/{id}/keys/{uuid}",
+		"/v2/users/abc123": "/v2/users/abc123",
+		"/users/12a":       "/users/12a",
+		"/keys/6ba7b810-9dad-11d1-80b4-00c04fd430cz": "/keys/6ba7b810-9dad-11d1-80b4-00c04fd430cz",
+		"/":          "/",
+		"/users/42/": "/users/{id}/",
+	}
+	for path, want := range tests {
+		if got := TemplatePath(path); got != want {
+			t.Errorf("TemplatePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestPathTemplateRoute(t *testing.T) {
+	l, _ := newTestLogger(WithPathTemplate(nil))
+	r := httptest.NewRequest("GET", "/users/42?full=1", nil)
+	if got := routeOf(t, l, r); got != "/users/{id}" {
+		t.Errorf("route = %v, want /users/{id}", got)
+	}
+
+	l, _ = newTestLogger(WithPathTemplate(func(p string) string { return "custom" }))
+	if got := routeOf(t, l, httptest.NewRequest("GET", "/users/42", nil)); got != "custom" {
+		t.Errorf("route = %v, want custom", got)
+	}
+}
+
+func TestNormalizeTrailingSlash(t *testing.T) {
+	tests := map[string]string{
+		"/":            "/",
+		"/a":           "/a",
+		"/a/":          "/a",
+		"/a/b/":        "/a/b",
+		"/a/b/?q=1":    "/a/b?q=1",
+		"GET /":        "GET /",
+		"GET /a/":      "GET /a",
+		"GET /a/{id}/": "GET /a/{id}",
+	}
+	for route, want := range tests {
+		if got := trimTrailingSlash(route); got != want {
+			t.Errorf("trimTrailingSlash(%q) = %q, want %q", route, got, want)
+		}
+	}
+
+	for path, want := range map[string]string{"/": "/", "/a/": "/a", "/a/b/": "/a/b"} {
+		l, _ := newTestLogger(WithNormalizeTrailingSlash())
+		if got := routeOf(t, l, httptest.NewRequest("GET", path, nil)); got != want {
+			t.Errorf("route of %s = %v, want %s", path, got, want)
+		}
+	}
+}
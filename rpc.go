@@ -0,0 +1,115 @@
+package babylogger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RPC describes a completed remote procedure call. It lets RPC frameworks log
+// calls with the same look as HTTP requests; see the grpc sub-package for a
+// ready-made gRPC interceptor.
+type RPC struct {
+	Method   string        // full method name, e.g. /pkg.Service/Method
+	Code     string        // readable status code, e.g. OK or NotFound
+	Status   int           // HTTP equivalent of Code, used for coloring
+	Duration time.Duration // time spent handling the call
+	PeerAddr string        // client address, if known
+}
+
+// LogRPC logs a completed RPC. This is a separate code path from the HTTP
+// middleware: RPCs are logged on a single line, and don't count towards the
+// Logger's Stats or reach its EntryWriters.
+//
+// The line follows the Logger's format, or goes through its slog.Logger if
+// it has one, with the level and error sink picked from Status. The Nginx
+// format has no room for RPCs, so they aren't logged with it.
+func (l *Logger) LogRPC(rpc RPC) {
+	level := l.level(rpc.Status)
+	if l.slog != nil {
+		lvl := slog.LevelInfo
+		switch level {
+		case "warn":
+			lvl = slog.LevelWarn
+		case "error":
+			lvl = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("rpc_method", rpc.Method),
+			slog.String("rpc_code", rpc.Code),
+			slog.Int("status", rpc.Status),
+			slog.Duration("duration", rpc.Duration),
+		}
+		if rpc.PeerAddr != "" {
+			attrs = append(attrs, slog.String("remote_addr", rpc.PeerAddr))
+		}
+		l.slog.LogAttrs(context.Background(), lvl, rpc.Method+" "+rpc.Code, attrs...)
+		return
+	}
+
+	now := l.now()
+	switch l.format {
+	case JSON:
+		n := l.fieldNames
+		var fields []Attr
+		field := func(name string, value interface{}) {
+			if name != "" {
+				fields = append(fields, Attr{name, value})
+			}
+		}
+		field(n.Time, now.Format(time.RFC3339Nano))
+		field(n.Level, level)
+		field(n.Message, rpc.Method+" "+rpc.Code)
+		fields = append(fields, Attr{"rpc_method", rpc.Method}, Attr{"rpc_code", rpc.Code})
+		field(n.Status, rpc.Status)
+		field(n.Duration, rpc.Duration)
+		if rpc.PeerAddr != "" {
+			field(n.RemoteAddr, rpc.PeerAddr)
+		}
+		l.writeJSON(rpc.Status, fields)
+
+	case GCP:
+		attrs := []Attr{
+			{"rpc_method", rpc.Method},
+			{"rpc_code", rpc.Code},
+			{"status", rpc.Status},
+			{"latency", fmt.Sprintf("%.9fs", rpc.Duration.Seconds())},
+		}
+		if rpc.PeerAddr != "" {
+			attrs = append(attrs, Attr{"remote_addr", rpc.PeerAddr})
+		}
+		l.logGCPLine(rpc.Status, nil, now, level, rpc.Method+" "+rpc.Code, attrs)
+
+	case Nginx:
+		// nginx's access log has no room for RPCs
+
+	case ECS:
+		var o ecsObject
+		o.set("@timestamp", now.UTC().Format(time.RFC3339Nano))
+		o.set("log.level", level)
+		o.set("message", rpc.Method+" "+rpc.Code)
+		o.set("ecs.version", ecsVersion)
+		o.set("event.duration", rpc.Duration.Nanoseconds())
+		if rpc.PeerAddr != "" {
+			o.set("client.address", rpc.PeerAddr)
+		}
+		o.set("babylogger.rpc_method", rpc.Method)
+		o.set("babylogger.rpc_code", rpc.Code)
+		o.set("babylogger.status", rpc.Status)
+		l.writeJSON(rpc.Status, o)
+
+	default:
+		t := l.styles()
+		arrow := t.Subtle.Render("->")
+		method := t.URI.Render(rpc.Method)
+		code := l.statusStyle(rpc.Status).Render(rpc.Code)
+		duration := t.Duration.Render(rpc.Duration.String())
+
+		line := fmt.Sprintf("%s %s %s %s", arrow, method, code, duration)
+		if rpc.PeerAddr != "" {
+			line += " " + t.Address.Render(rpc.PeerAddr)
+		}
+		l.printStatus(rpc.Status, line)
+	}
+}
@@ -0,0 +1,59 @@
+package babylogger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogRPCJSON(t *testing.T) {
+	l, out := newTestLogger(WithFormat(JSON))
+	l.LogRPC(RPC{Method: "/pkg.Service/Get", Code: "NotFound", Status: 404, Duration: time.Millisecond, PeerAddr: "192.0.2.1"})
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("line isn't JSON: %v\n%s", err, out.String())
+	}
+	want := map[string]interface{}{
+		"level":       "warn",
+		"rpc_method":  "/pkg.Service/Get",
+		"rpc_code":    "NotFound",
+		"status":      float64(404),
+		"remote_addr": "192.0.2.1",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestLogRPCErrorSink(t *testing.T) {
+	sink := new(syncBuffer)
+	l, out := newTestLogger(WithErrorSink(sink, 500))
+	l.LogRPC(RPC{Method: "/pkg.Service/Get", Code: "OK", Status: 200})
+	l.LogRPC(RPC{Method: "/pkg.Service/Put", Code: "Unavailable", Status: 503})
+
+	if got := out.String(); !strings.Contains(got, "/pkg.Service/Get OK") || strings.Contains(got, "Unavailable") {
+		t.Errorf("unexpected output:\n%s", got)
+	}
+	if got := sink.String(); !strings.Contains(got, "/pkg.Service/Put Unavailable") {
+		t.Errorf("failed RPC not sent to the error sink:\n%s", got)
+	}
+}
+
+func TestLogRPCSlog(t *testing.T) {
+	out := new(syncBuffer)
+	l := New(WithSlog(slog.New(slog.NewJSONHandler(out, nil))))
+	l.LogRPC(RPC{Method: "/pkg.Service/Put", Code: "Internal", Status: 500})
+
+	got := out.String()
+	if !strings.Contains(got, `"level":"ERROR"`) || !strings.Contains(got, `"rpc_code":"Internal"`) {
+		t.Errorf("RPC not logged through slog:\n%s", got)
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Errorf("colors in slog output:\n%q", got)
+	}
+}
@@ -0,0 +1,59 @@
+package babylogger
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+type sampledKey struct{}
+
+// WithSampledTracing logs only a share of requests, picked at random with
+// probability rate, except that requests that fail with a 5xx or take
+// longer than slow are always logged. Set slow to 0 to only always log
+// failures.
+//
+// Whether a request is sampled is decided as soon as it comes in, so that
+// tracing in the handler can follow the same decision; handlers get it with
+// SampledFromContext. A request that wasn't sampled but then fails or is
+// slow is upgraded: it's logged anyway, with sample_upgraded=true. Tracers
+// that want the same guarantee need to buffer the spans of unsampled
+// requests until they end, like the logger does.
+//
+// To upgrade requests, the request line of those that aren't sampled is
+// held back until their response is known, and then logged along with the
+// response line, or not at all. Sampled requests are logged as usual.
+func WithSampledTracing(rate float64, slow time.Duration) Option {
+	return func(l *Logger) {
+		l.tracing = &tracingSampler{rate: rate, slow: slow}
+	}
+}
+
+// SampledFromContext reports whether the request ctx belongs to was sampled
+// by WithSampledTracing. Requests are sampled when WithSampledTracing isn't
+// used.
+func SampledFromContext(ctx context.Context) bool {
+	sampled, ok := ctx.Value(sampledKey{}).(bool)
+	return sampled || !ok
+}
+
+// tracingSampler holds the settings of WithSampledTracing.
+type tracingSampler struct {
+	rate float64
+	slow time.Duration
+}
+
+// sample decides whether a request is sampled and returns it with the
+// decision in its context.
+func (t *tracingSampler) sample(r *http.Request, e *Entry) *http.Request {
+	e.unsampled = rand.Float64() >= t.rate
+	ctx := context.WithValue(r.Context(), sampledKey{}, !e.unsampled)
+	e.ctx = ctx
+	return r.WithContext(ctx)
+}
+
+// upgrade reports whether an unsampled request gets logged anyway.
+func (t *tracingSampler) upgrade(e *Entry) bool {
+	return e.Status >= 500 || (t.slow > 0 && e.Duration >= t.slow)
+}
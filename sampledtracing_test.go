@@ -0,0 +1,71 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampledTracingUpgrade(t *testing.T) {
+	l, out := newTestLogger(WithSampledTracing(0, 5*time.Millisecond))
+	var sampled []bool
+	h := func(w http.ResponseWriter, r *http.Request) {
+		sampled = append(sampled, SampledFromContext(r.Context()))
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/slow":
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	serveTest(l, h, httptest.NewRequest("GET", "/ok", nil))
+	if got := out.String(); got != "" {
+		t.Fatalf("unsampled successful request logged:\n%s", got)
+	}
+
+	serveTest(l, h, httptest.NewRequest("GET", "/fail", nil))
+	got := lines(out)
+	if len(got) != 2 {
+		t.Fatalf("got %d lines for an unsampled failure, want 2:\n%s", len(got), out.String())
+	}
+	if !strings.Contains(got[0], "<- GET /fail") {
+		t.Errorf("request line not logged: %q", got[0])
+	}
+	if !strings.Contains(got[1], "-> 502") || !strings.Contains(got[1], "sample_upgraded=true") {
+		t.Errorf("response line not upgraded: %q", got[1])
+	}
+
+	serveTest(l, h, httptest.NewRequest("GET", "/slow", nil))
+	if got := lines(out); len(got) != 4 || !strings.Contains(got[3], "sample_upgraded=true") {
+		t.Errorf("unsampled slow request not upgraded:\n%s", out.String())
+	}
+
+	for i, s := range sampled {
+		if s {
+			t.Errorf("request %d sampled at rate 0", i)
+		}
+	}
+}
+
+func TestSampledTracingSampled(t *testing.T) {
+	l, out := newTestLogger(WithSampledTracing(1, 0))
+	var sampled bool
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		sampled = SampledFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	}, httptest.NewRequest("GET", "/", nil))
+
+	if !sampled {
+		t.Error("request not sampled at rate 1")
+	}
+	got := out.String()
+	if !strings.Contains(got, "-> 500") {
+		t.Errorf("sampled request not logged:\n%s", got)
+	}
+	if strings.Contains(got, "sample_upgraded") {
+		t.Errorf("sampled request marked as upgraded:\n%s", got)
+	}
+}
@@ -0,0 +1,101 @@
+package babylogger
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+// Sampler decides which requests get logged, for servers with too much
+// traffic to log every request. It's consulted once the response is known,
+// with its status.
+//
+// Sample takes the status along with the request, rather than deciding from
+// the request alone, so that samplers like StatusSampler can keep every
+// failure while dropping most successes. Samplers that don't care about the
+// outcome can ignore it.
+type Sampler interface {
+	Sample(r *http.Request, status int) bool
+}
+
+// SamplerFunc is an adapter to use an ordinary function as a Sampler.
+type SamplerFunc func(r *http.Request, status int) bool
+
+// Sample implements Sampler.
+func (f SamplerFunc) Sample(r *http.Request, status int) bool {
+	return f(r, status)
+}
+
+// WithSampler logs only the requests s samples. Like with WithLogDecider, the
+// request line is held back until the response is known, and EntryWriters
+// still receive every entry. For example, to log all server errors and one
+// in ten other requests:
+//
+//	babylogger.WithSampler(babylogger.CompositeSampler(
+//		babylogger.StatusSampler(500, 502, 503, 504),
+//		babylogger.RateSampler(0.1),
+//	))
+func WithSampler(s Sampler) Option {
+	return func(l *Logger) {
+		l.settings.sampler = s
+	}
+}
+
+// RateSampler samples requests at random with the given probability, from 0
+// (none) to 1 (all).
+func RateSampler(rate float64) Sampler {
+	return SamplerFunc(func(*http.Request, int) bool {
+		return rand.Float64() < rate
+	})
+}
+
+// StatusSampler samples the requests whose response has one of the given
+// statuses, and no others.
+func StatusSampler(statuses ...int) Sampler {
+	set := make(map[int]bool, len(statuses))
+	for _, s := range statuses {
+		set[s] = true
+	}
+	return SamplerFunc(func(_ *http.Request, status int) bool {
+		return set[status]
+	})
+}
+
+// PathSampler samples requests whose path starts with prefix at random with
+// the given probability. All other requests are sampled.
+func PathSampler(prefix string, rate float64) Sampler {
+	return SamplerFunc(func(r *http.Request, _ int) bool {
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+		return rand.Float64() < rate
+	})
+}
+
+// CompositeSampler combines samplers with OR: it samples the requests that
+// any of the given samplers samples. Use AllSampler to combine them with
+// AND instead.
+func CompositeSampler(samplers ...Sampler) Sampler {
+	return SamplerFunc(func(r *http.Request, status int) bool {
+		for _, s := range samplers {
+			if s.Sample(r, status) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// AllSampler combines samplers with AND: it samples the requests that all of
+// the given samplers sample. Use CompositeSampler to combine them with OR
+// instead.
+func AllSampler(samplers ...Sampler) Sampler {
+	return SamplerFunc(func(r *http.Request, status int) bool {
+		for _, s := range samplers {
+			if !s.Sample(r, status) {
+				return false
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,86 @@
+package babylogger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ServerErrorLog returns a *log.Logger for http.Server's ErrorLog, created
+// with the given options. See Logger.ServerErrorLog.
+func ServerErrorLog(opts ...Option) *log.Logger {
+	return New(opts...).ServerErrorLog()
+}
+
+// ServerErrorLog returns a *log.Logger that writes through this logger, for
+// use as an http.Server's ErrorLog:
+//
+//	server.ErrorLog = logger.ServerErrorLog()
+//
+// The server logs low-level errors there, like failed TLS handshakes and
+// malformed requests, which never reach a handler. This way they end up in
+// the same stream as the request logs, in the same format, at error level and
+// tagged with server_error=true so they can be told apart from handler errors.
+func (l *Logger) ServerErrorLog() *log.Logger {
+	return log.New(serverErrorWriter{l}, "", 0)
+}
+
+// serverErrorWriter receives messages from a log.Logger, one per Write.
+type serverErrorWriter struct {
+	l *Logger
+}
+
+func (w serverErrorWriter) Write(p []byte) (int, error) {
+	w.l.logServerError(string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+// logServerError logs an error reported by the server itself.
+func (l *Logger) logServerError(msg string) {
+	if l.slog != nil {
+		l.slog.LogAttrs(context.Background(), slog.LevelError, msg, slog.Bool("server_error", true))
+		return
+	}
+
+	now := l.now()
+	switch l.format {
+	case JSON:
+		n := l.fieldNames
+		var fields []Attr
+		if n.Time != "" {
+			fields = append(fields, Attr{n.Time, now.Format(time.RFC3339Nano)})
+		}
+		if n.Level != "" {
+			fields = append(fields, Attr{n.Level, "error"})
+		}
+		msgKey := n.Message
+		if msgKey == "" {
+			msgKey = "error"
+		}
+		fields = append(fields, Attr{msgKey, msg}, Attr{"server_error", true})
+		l.writeJSON(http.StatusInternalServerError, fields)
+
+	case GCP:
+		l.logGCPLine(http.StatusInternalServerError, nil, now, "error", msg, []Attr{{"server_error", true}})
+
+	case Nginx:
+		l.logNginxError(msg)
+
+	case ECS:
+		var o ecsObject
+		o.set("@timestamp", now.UTC().Format(time.RFC3339Nano))
+		o.set("log.level", "error")
+		o.set("message", msg)
+		o.set("ecs.version", ecsVersion)
+		o.set("error.message", msg)
+		o.set("babylogger.server_error", true)
+		l.writeJSON(http.StatusInternalServerError, o)
+
+	default:
+		t := l.styles()
+		l.printStatus(http.StatusInternalServerError, t.Status5xx.Render("!! "+msg)+l.renderAttrs([]Attr{{"server_error", true}}))
+	}
+}
@@ -0,0 +1,34 @@
+package babylogger
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WithServerTiming adds a Server-Timing header to responses with the time
+// spent in the handler, so it shows up in browser developer tools and
+// front-end performance tooling:
+//
+//	Server-Timing: total;dur=12.3
+//
+// Headers can't be changed once they've been sent, so the duration is
+// measured when the handler writes the header (explicitly, or implicitly with
+// its first write or flush) rather than when it returns. For most handlers
+// that's close enough, but for streamed responses it only covers the time
+// until the first byte. Handlers that never write anything get the full
+// duration.
+func WithServerTiming() Option {
+	return func(l *Logger) {
+		l.serverTiming = true
+	}
+}
+
+// serverTimingHook returns a function that sets the Server-Timing header with
+// the time elapsed since start.
+func serverTimingHook(start time.Time) func(http.Header) {
+	return func(h http.Header) {
+		ms := float64(time.Since(start)) / float64(time.Millisecond)
+		h.Add("Server-Timing", fmt.Sprintf("total;dur=%.1f", ms))
+	}
+}
@@ -0,0 +1,188 @@
+package babylogger
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithShadowHandler sends a copy of each request to a shadow handler, like a
+// new implementation being tested against production traffic, and logs how
+// its response compares to the real one:
+//
+//	~> GET /users shadow shadow_match=true shadow_status=200 shadow_bytes=1024 primary_status=200 primary_bytes=1024
+//
+// The responses match when their status, size and the given headers are
+// equal. The shadow handler runs concurrently, in its own goroutine, with a
+// context that isn't canceled when the client goes away; its response is
+// discarded. Request bodies are read into memory up front so both handlers
+// can read them.
+//
+// To keep the cost of shadowing in check, requests with bodies over 1MB
+// aren't shadowed, and neither are requests that come in while 64 shadow
+// handlers are already running.
+//
+// Requests that are blocked or rate limited aren't shadowed.
+func WithShadowHandler(shadow http.Handler, compareHeaders ...string) Option {
+	return func(l *Logger) {
+		l.shadow = shadow
+		l.shadowHeaders = compareHeaders
+	}
+}
+
+// WithShadowBodyComparison makes WithShadowHandler compare response bodies
+// too. Bodies are hashed as they're written rather than kept in memory, but
+// hashing every response costs CPU time, so this is off by default.
+func WithShadowBodyComparison() Option {
+	return func(l *Logger) {
+		l.shadowBodies = true
+	}
+}
+
+const (
+	// maxShadowBody is the largest request body that's shadowed.
+	maxShadowBody = 1 << 20
+
+	// maxShadows is how many shadow handlers may run at once.
+	maxShadows = 64
+)
+
+// shadowResponse is what's compared between the primary and shadow
+// responses.
+type shadowResponse struct {
+	status int
+	bytes  int64
+	header http.Header
+	sum    []byte // body hash, if bodies are compared
+	err    interface{}
+}
+
+// shadowRecorder is the ResponseWriter given to the shadow handler.
+type shadowRecorder struct {
+	header      http.Header
+	status      int
+	bytes       int64
+	hash        hash.Hash
+	wroteHeader bool
+}
+
+func (s *shadowRecorder) Header() http.Header {
+	return s.header
+}
+
+func (s *shadowRecorder) WriteHeader(code int) {
+	if !s.wroteHeader {
+		s.status = code
+		s.wroteHeader = true
+	}
+}
+
+func (s *shadowRecorder) Write(p []byte) (int, error) {
+	s.wroteHeader = true
+	s.bytes += int64(len(p))
+	if s.hash != nil {
+		s.hash.Write(p)
+	}
+	return len(p), nil
+}
+
+// startShadow sends a copy of r to the shadow handler. It returns the request
+// the primary handler should serve, whose body has been buffered, and a
+// channel that receives the shadow response. If the request isn't shadowed,
+// because its body can't be read or is too large, or because too many
+// shadows are running, the returned channel is nil.
+func (l *Logger) startShadow(w *logWriter, r *http.Request) (*http.Request, <-chan shadowResponse) {
+	select {
+	case l.shadowSem <- struct{}{}:
+	default:
+		return r, nil
+	}
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxShadowBody+1))
+		if err != nil || len(body) > maxShadowBody {
+			// Let the primary handler see what was read, and the rest
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+			<-l.shadowSem
+			return r, nil
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	sr := r.Clone(context.WithoutCancel(r.Context()))
+	if body != nil {
+		sr.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	rec := &shadowRecorder{header: make(http.Header), status: http.StatusOK}
+	if l.shadowBodies {
+		rec.hash = sha256.New()
+		w.bodyHash = sha256.New()
+	}
+
+	done := make(chan shadowResponse, 1)
+	go func() {
+		res := shadowResponse{}
+		defer func() {
+			<-l.shadowSem
+			res.err = recover()
+			res.status = rec.status
+			res.bytes = rec.bytes
+			res.header = rec.header
+			if rec.hash != nil {
+				res.sum = rec.hash.Sum(nil)
+			}
+			done <- res
+		}()
+		l.shadow.ServeHTTP(rec, sr)
+	}()
+
+	return r, done
+}
+
+// compareShadow waits for the shadow response and logs how it compares to
+// the primary one.
+func (l *Logger) compareShadow(e *Entry, primary shadowResponse, shadow <-chan shadowResponse) {
+	s := <-shadow
+
+	attrs := []Attr{{"shadow_match", false}}
+	if s.err != nil {
+		attrs = append(attrs, Attr{"shadow_panic", fmt.Sprint(s.err)})
+	}
+	attrs = append(attrs,
+		Attr{"shadow_status", s.status},
+		Attr{"shadow_bytes", s.bytes},
+		Attr{"primary_status", primary.status},
+		Attr{"primary_bytes", primary.bytes},
+	)
+
+	match := s.err == nil && s.status == primary.status && s.bytes == primary.bytes
+	var diff []string
+	for _, h := range l.shadowHeaders {
+		if primary.header.Get(h) != s.header.Get(h) {
+			diff = append(diff, h)
+		}
+	}
+	if len(diff) > 0 {
+		match = false
+		attrs = append(attrs, Attr{"shadow_header_diff", strings.Join(diff, ",")})
+	}
+	if l.shadowBodies && !bytes.Equal(primary.sum, s.sum) {
+		match = false
+		attrs = append(attrs, Attr{"shadow_body_diff", true})
+	}
+	attrs[0].Value = match
+
+	l.logEvent(e, "shadow", attrs...)
+}
@@ -0,0 +1,50 @@
+package babylogger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShadowWithPanicRecovery(t *testing.T) {
+	called := make(chan struct{}, 1)
+	shadow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called <- struct{}{}
+	})
+	l, _ := newTestLogger(WithPanicRecovery(), WithShadowHandler(shadow))
+
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("shadow handler wasn't called with panic recovery on")
+	}
+}
+
+func TestShadowSkipsLargeBodies(t *testing.T) {
+	called := make(chan struct{}, 1)
+	shadow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called <- struct{}{}
+	})
+	l, _ := newTestLogger(WithShadowHandler(shadow))
+
+	body := strings.Repeat("x", maxShadowBody+1)
+	var got int
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = len(b)
+	}, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+	if got != len(body) {
+		t.Errorf("handler read %d bytes, want %d", got, len(body))
+	}
+	select {
+	case <-called:
+		t.Error("request with a body over the limit was shadowed")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,136 @@
+package babylogger
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// WithSlog logs requests through a structured slog.Logger instead of the
+// standard logger. Each request is logged as a single record once the
+// response has been sent, at Info level for successful responses, Warn for
+// 4xx and Error for 5xx. The Format option has no effect when logging through
+// slog.
+func WithSlog(logger *slog.Logger) Option {
+	return func(l *Logger) {
+		l.slog = logger
+	}
+}
+
+// WithContextLogger resolves the slog.Logger to log each request with from
+// the request's context. This is useful when upstream middleware attaches a
+// logger carrying request-scoped fields, like a trace ID, to the context: the
+// access log then inherits those fields. When fn returns nil the logger given
+// to WithSlog is used, or the standard logger if there isn't one.
+//
+// To derive a logger for each request and put it in the context instead, see
+// WithChildLogger.
+func WithContextLogger(fn func(context.Context) *slog.Logger) Option {
+	return func(l *Logger) {
+		l.contextLogger = fn
+	}
+}
+
+type slogKey struct{}
+
+// WithChildLogger derives a slog.Logger for each request from the one given
+// to WithSlog, or from slog.Default if there isn't one, so fields like a
+// tenant ID can be set once and carried by everything logged about the
+// request:
+//
+//	babylogger.WithChildLogger(func(ctx context.Context, l *slog.Logger) *slog.Logger {
+//		return l.With("tenant", TenantFromContext(ctx))
+//	})
+//
+// fn is called with the request's context once the request ID, if any, is
+// in it. The logger it returns is stored in the context, where handlers get
+// it with SlogFromContext, and when logging through WithSlog the request's
+// own record is written with it too. If fn returns nil the parent is used.
+//
+// This is the slog counterpart of WithRequestIDInLogPrefix; WithContextLogger
+// goes the other way, taking the logger from the context instead.
+//
+// It's named WithChildLogger rather than WithContextLogger, and handlers get
+// the logger with SlogFromContext rather than LoggerFromContext, because
+// those names were already taken: WithContextLogger resolves the logger to
+// log with from the context, and LoggerFromContext returns the *log.Logger
+// set up by WithRequestIDInLogPrefix.
+func WithChildLogger(fn func(context.Context, *slog.Logger) *slog.Logger) Option {
+	return func(l *Logger) {
+		l.childLogger = fn
+	}
+}
+
+// SlogFromContext returns the request's logger stored in ctx by
+// WithChildLogger, or slog.Default if there isn't one.
+func SlogFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(slogKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// addChildLogger derives the request's logger and returns the request with
+// the logger in its context.
+func (l *Logger) addChildLogger(r *http.Request, e *Entry) *http.Request {
+	parent := l.slog
+	if parent == nil {
+		parent = slog.Default()
+	}
+	child := l.childLogger(r.Context(), parent)
+	if child == nil {
+		child = parent
+	}
+	e.child = child
+
+	ctx := context.WithValue(r.Context(), slogKey{}, child)
+	e.ctx = ctx
+	return r.WithContext(ctx)
+}
+
+// slogger returns the slog.Logger to log an entry with, if any.
+func (l *Logger) slogger(e *Entry) *slog.Logger {
+	if l.contextLogger != nil && e.ctx != nil {
+		if logger := l.contextLogger(e.ctx); logger != nil {
+			return logger
+		}
+	}
+	if l.slog != nil && e.child != nil {
+		return e.child
+	}
+	return l.slog
+}
+
+// logSlog logs an entry as a single slog record.
+func (l *Logger) logSlog(logger *slog.Logger, e *Entry) {
+	lvl := slog.LevelInfo
+	switch l.level(e.Status) {
+	case "warn":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", e.Method),
+		slog.String("uri", e.URI),
+		slog.String("path", e.Path),
+		slog.String("proto", e.Proto),
+		slog.String("remote_addr", e.RemoteAddr),
+		slog.Int("status", e.Status),
+		slog.Int("bytes", e.Bytes),
+		slog.Duration("duration", e.Duration),
+	}
+	if l.rateLimited(e.Status) && !e.has("rate_limited") {
+		attrs = append(attrs, slog.Bool("rate_limited", true))
+	}
+	for _, a := range e.Attrs {
+		attrs = append(attrs, slog.Any(a.Key, a.Value))
+	}
+
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger.LogAttrs(ctx, lvl, e.Method+" "+e.URI, attrs...)
+}
@@ -0,0 +1,53 @@
+package babylogger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// unbase62 parses a base 62 number formatted by base62.
+func unbase62(s string) uint64 {
+	var n uint64
+	for _, c := range s {
+		n = n*62 + uint64(strings.IndexRune(base62Digits, c))
+	}
+	return n
+}
+
+func TestSnowflakeClockBackwards(t *testing.T) {
+	now := time.Now()
+	s := &snowflake{node: 7, clock: func() time.Time { return now }}
+	s.next()
+
+	// The clock goes back a second and stays there, while more IDs are
+	// generated than fit in a millisecond
+	now = now.Add(-time.Second)
+	ids := make(chan []string)
+	go func() {
+		var got []string
+		for i := 0; i < 3*(snowflakeMaxSeq+1); i++ {
+			got = append(got, s.next())
+		}
+		ids <- got
+	}()
+
+	var got []string
+	select {
+	case got = <-ids:
+	case <-time.After(5 * time.Second):
+		t.Fatal("generating IDs blocked while the clock was behind")
+	}
+
+	var last uint64
+	for i, id := range got {
+		n := unbase62(id)
+		if n <= last {
+			t.Fatalf("ID %d (%s) not greater than the one before", i, id)
+		}
+		if node := n >> snowflakeSeqBits & snowflakeMaxNode; node != 7 {
+			t.Fatalf("ID %d (%s) has node %d, want 7", i, id, node)
+		}
+		last = n
+	}
+}
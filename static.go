@@ -0,0 +1,40 @@
+package babylogger
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultStaticExtensions returns the file extensions
+// WithSkipStaticExtensions skips when it isn't given any.
+func DefaultStaticExtensions() []string {
+	return []string{".js", ".css", ".png", ".jpg", ".svg", ".ico", ".woff2"}
+}
+
+// WithSkipStaticExtensions skips logging successful requests for static
+// assets, whose paths end in one of the given extensions, like .css. Requests
+// that fail, with a 4xx or 5xx status, are still logged. Extensions are
+// matched regardless of case. Without extensions, the ones returned by
+// DefaultStaticExtensions are used.
+//
+// Like with WithLogDecider, the request line of static assets is held back
+// until the response is known. EntryWriters still receive every entry.
+func WithSkipStaticExtensions(exts ...string) Option {
+	if len(exts) == 0 {
+		exts = DefaultStaticExtensions()
+	}
+	return func(l *Logger) {
+		l.staticExts = make(map[string]bool, len(exts))
+		for _, ext := range exts {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			l.staticExts[strings.ToLower(ext)] = true
+		}
+	}
+}
+
+// isStatic reports whether p is the path of a static asset.
+func (l *Logger) isStatic(p string) bool {
+	return len(l.staticExts) > 0 && l.staticExts[strings.ToLower(path.Ext(p))]
+}
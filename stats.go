@@ -0,0 +1,62 @@
+package babylogger
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a point-in-time snapshot of a Logger's request counters.
+type Stats struct {
+	Requests  int64 `json:"requests"`   // completed requests
+	Status1xx int64 `json:"status_1xx"` // informational responses
+	Status2xx int64 `json:"status_2xx"` // successful responses
+	Status3xx int64 `json:"status_3xx"` // redirects
+	Status4xx int64 `json:"status_4xx"` // client errors
+	Status5xx int64 `json:"status_5xx"` // server errors
+	Bytes     int64 `json:"bytes"`      // response body bytes written
+	InFlight  int64 `json:"in_flight"`  // requests currently being served
+	Dropped   int64 `json:"dropped"`    // log lines dropped, see WithLogTimeout
+}
+
+// count records a completed request.
+func (l *Logger) count(code, bytes int) {
+	atomic.AddInt64(&l.requests, 1)
+	atomic.AddInt64(&l.bytes, int64(bytes))
+	if class := code / 100; class > 0 && class < len(l.classes) {
+		atomic.AddInt64(&l.classes[class], 1)
+	}
+	l.recent.record(time.Now(), code)
+}
+
+// Stats returns a snapshot of the requests served by this Logger. Counters
+// are read individually, so a snapshot taken under load may be off by a
+// request or two between fields.
+func (l *Logger) Stats() Stats {
+	return Stats{
+		Requests:  atomic.LoadInt64(&l.requests),
+		Status1xx: atomic.LoadInt64(&l.classes[1]),
+		Status2xx: atomic.LoadInt64(&l.classes[2]),
+		Status3xx: atomic.LoadInt64(&l.classes[3]),
+		Status4xx: atomic.LoadInt64(&l.classes[4]),
+		Status5xx: atomic.LoadInt64(&l.classes[5]),
+		Bytes:     atomic.LoadInt64(&l.bytes),
+		InFlight:  atomic.LoadInt64(&l.inFlight),
+		Dropped:   atomic.LoadInt64(&l.dropped),
+	}
+}
+
+// StatsHandler returns an http.Handler that serves the Logger's Stats as
+// JSON. It doesn't depend on the request path, so it can be mounted anywhere:
+//
+//	l := babylogger.New()
+//	http.Handle("/", l.Middleware(mux))
+//	http.Handle("/debug/stats", l.StatsHandler())
+func (l *Logger) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(l.Stats())
+	})
+}
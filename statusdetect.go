@@ -0,0 +1,53 @@
+package babylogger
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// WithResponseStatusOverrideDetection guards against logging the wrong
+// status for handlers that write around the logger. Babylogger learns the
+// status from calls to its ResponseWriter, but middleware further down can
+// unwrap it (see http.ResponseController) and write to the writer beneath
+// directly. When the handler returns without the logger having seen a
+// status or a write, and its writer was unwrapped:
+//
+//   - if a writer beneath reports the status it sent, through a
+//     Status() int method like many middleware libraries' writers have, that
+//     status is logged, along with status_recovered=true
+//   - otherwise status_unknown=true is logged, since the logged 200 is only
+//     a guess
+func WithResponseStatusOverrideDetection() Option {
+	return func(l *Logger) {
+		l.detectStatus = true
+	}
+}
+
+// statusReporter is implemented by ResponseWriters that report the status
+// they sent.
+type statusReporter interface {
+	Status() int
+}
+
+// detectStatus checks whether the status may have been written around the
+// writer, and recovers it if possible.
+func detectStatus(w *logWriter, e *Entry) {
+	if w.headerWritten() || atomic.LoadInt32(&w.unwrapped) == 0 {
+		return
+	}
+	for rw := w.ResponseWriter; rw != nil; {
+		if s, ok := rw.(statusReporter); ok {
+			if code := s.Status(); code != 0 {
+				w.code = code
+				e.add("status_recovered", true)
+				return
+			}
+		}
+		u, ok := rw.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		rw = u.Unwrap()
+	}
+	e.add("status_unknown", true)
+}
@@ -0,0 +1,77 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// statusWriter is a ResponseWriter that reports the status it sent.
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Status() int { return w.code }
+
+// writeAround is a handler that doesn't cooperate with the logger: it
+// unwraps the logger's writer and writes a 418 to the writer beneath.
+func writeAround(w http.ResponseWriter, r *http.Request) {
+	w.(interface{ Unwrap() http.ResponseWriter }).Unwrap().WriteHeader(http.StatusTeapot)
+}
+
+func TestResponseStatusOverrideDetection(t *testing.T) {
+	tests := []struct {
+		name    string
+		w       func() http.ResponseWriter
+		h       http.HandlerFunc
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "recovered",
+			w:    func() http.ResponseWriter { return &statusWriter{ResponseWriter: httptest.NewRecorder()} },
+			h:    writeAround,
+			want: []string{"-> 418", "status_recovered=true"},
+		},
+		{
+			name:    "unknown",
+			w:       func() http.ResponseWriter { return httptest.NewRecorder() },
+			h:       writeAround,
+			want:    []string{"-> 200", "status_unknown=true"},
+			notWant: []string{"status_recovered"},
+		},
+		{
+			name: "cooperating",
+			w:    func() http.ResponseWriter { return &statusWriter{ResponseWriter: httptest.NewRecorder()} },
+			h: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			},
+			want:    []string{"-> 202"},
+			notWant: []string{"status_recovered", "status_unknown"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, out := newTestLogger(WithResponseStatusOverrideDetection())
+			l.Middleware(tt.h).ServeHTTP(tt.w(), httptest.NewRequest("GET", "/", nil))
+			got := out.String()
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("%q missing from:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("unexpected %q in:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package babylogger
+
+import "net/http"
+
+// WithStatusText sets the function that gives the text logged after status
+// codes on the pretty response line, like the OK in 200 OK, in place of
+// http.StatusText. This allows for localized or custom status phrases, like
+// 200 SUCCESS. When fn returns an empty string only the code is logged.
+// Passing nil restores http.StatusText.
+func WithStatusText(fn func(code int) string) Option {
+	return func(l *Logger) {
+		if fn == nil {
+			fn = http.StatusText
+		}
+		l.statusText = fn
+	}
+}
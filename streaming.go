@@ -0,0 +1,119 @@
+package babylogger
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultStreamingThreshold is the response size from which
+// WithStreamingProgress logs progress, unless configured otherwise.
+const defaultStreamingThreshold = 1 << 20 // 1 MiB
+
+// WithStreamingProgress logs progress lines for large streamed responses,
+// like file downloads, every interval while they're being written. This helps
+// diagnose slow clients and partial downloads, which the final byte count and
+// duration alone don't explain. Progress is logged like:
+//
+//	bytes_sent_so_far=5242880 elapsed=2s
+//
+// Only responses that have been flushed (via http.Flusher) and have grown
+// past a size threshold are reported; see WithStreamingProgressThreshold. The
+// response line of such responses includes complete=true, or complete=false
+// if the client disconnected or a write failed before the handler finished.
+func WithStreamingProgress(interval time.Duration) Option {
+	return func(l *Logger) {
+		l.progressInterval = interval
+		l.progressFlushed = true
+		if l.progressThreshold == 0 {
+			l.progressThreshold = defaultStreamingThreshold
+		}
+	}
+}
+
+// WithStreamProgress logs progress lines for responses that are still being
+// written every interval, like server-sent events and NDJSON streams, which
+// are otherwise only logged once they end, possibly hours later:
+//
+//	~> GET /events progress bytes_sent_so_far=4096 elapsed=30s
+//
+// Unlike WithStreamingProgress, every response still open after the interval
+// is reported, whether it has been flushed or not and however much it has
+// written. Progress stops being logged when the response completes or the
+// client disconnects.
+//
+// Each request gets a goroutine and a timer to watch it, which is a small but
+// measurable cost for short requests that never need it. This is intended
+// for streaming endpoints only, so use a separate Logger for them rather than
+// enabling it server-wide.
+func WithStreamProgress(interval time.Duration) Option {
+	return func(l *Logger) {
+		l.progressInterval = interval
+		l.progressFlushed = false
+		l.progressThreshold = 0
+	}
+}
+
+// WithStreamingProgressThreshold sets the response size, in bytes, from
+// which WithStreamingProgress logs progress. It defaults to 1 MiB.
+func WithStreamingProgressThreshold(bytes int64) Option {
+	return func(l *Logger) {
+		l.progressThreshold = bytes
+	}
+}
+
+// watchProgress logs progress for a response every progressInterval until
+// the returned function is called or ctx is done. The returned function
+// reports whether any progress was logged, and can be called more than once.
+func (l *Logger) watchProgress(ctx context.Context, w *logWriter, e *Entry, start time.Time) (stop func() bool) {
+	done := make(chan struct{})
+	finished := make(chan bool)
+
+	go func() {
+		t := time.NewTicker(l.progressInterval)
+		defer t.Stop()
+
+		ticks, closed := t.C, ctx.Done()
+		var reported bool
+		for {
+			select {
+			case <-done:
+				finished <- reported
+				return
+			case <-closed:
+				// The client is gone; wait for the handler to notice
+				ticks, closed = nil, nil
+			case now := <-ticks:
+				if l.progressFlushed && atomic.LoadInt32(&w.flushed) == 0 {
+					continue
+				}
+				sent := atomic.LoadInt64(&w.bytes)
+				if sent < l.progressThreshold {
+					continue
+				}
+				reported = true
+				l.logEvent(e, "progress",
+					Attr{"bytes_sent_so_far", sent},
+					Attr{"elapsed", now.Sub(start).Round(time.Millisecond)},
+				)
+			}
+		}
+	}()
+
+	var once sync.Once
+	var reported bool
+	return func() bool {
+		once.Do(func() {
+			close(done)
+			reported = <-finished
+		})
+		return reported
+	}
+}
+
+// streamComplete reports whether a streamed response was written completely.
+func streamComplete(w *logWriter, r *http.Request) bool {
+	return atomic.LoadInt32(&w.writeErr) == 0 && r.Context().Err() == nil
+}
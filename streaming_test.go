@@ -0,0 +1,55 @@
+package babylogger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamProgress(t *testing.T) {
+	l, out := newTestLogger(WithStreamProgress(5 * time.Millisecond))
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("event"))
+		time.Sleep(30 * time.Millisecond)
+	}, httptest.NewRequest("GET", "/events", nil))
+
+	got := out.String()
+	if !strings.Contains(got, "progress bytes_sent_so_far=5") {
+		t.Errorf("progress not logged:\n%s", got)
+	}
+	if !strings.Contains(got, "complete=true") {
+		t.Errorf("response line not marked complete:\n%s", got)
+	}
+}
+
+func TestStreamProgressPanic(t *testing.T) {
+	l, out := newTestLogger(WithStreamProgress(5 * time.Millisecond))
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		func() {
+			defer func() { recover() }()
+			serveTest(l, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("event"))
+				panic("boom")
+			}, httptest.NewRequest("GET", "/events", nil))
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left running after panics, had %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	logged := out.String()
+	time.Sleep(20 * time.Millisecond)
+	if out.String() != logged {
+		t.Errorf("progress logged after the handler panicked:\n%s", out.String())
+	}
+}
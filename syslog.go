@@ -0,0 +1,164 @@
+package babylogger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syslogFacility is the syslog facility entries are logged with: local0.
+const syslogFacility = 16
+
+// Syslog severities.
+const (
+	syslogError   = 3
+	syslogWarning = 4
+	syslogInfo    = 6
+)
+
+// SyslogWriter sends logs to a remote syslog receiver, like Papertrail or
+// rsyslog, over TLS, as RFC 5424 messages framed per RFC 5425. It's both an
+// output and an EntryWriter. As an output, for WithOutput, each line is sent
+// as a message as is:
+//
+//	l := babylogger.New(babylogger.WithOutput(w))
+//
+// As an EntryWriter, each request is sent as a message of its own, with a
+// severity following its status and its details in structured data:
+//
+//	<134>1 2009-11-10T23:00:00.000000Z host app 42 access [babylogger@0 method="GET" uri="/users" status="200" duration="1.2ms"] GET /users 200 1.2ms
+//
+// Messages are sent with the local0 facility. Requests with 5xx statuses
+// get the error severity, those with 4xx warning and the rest informational;
+// lines written as an output are informational.
+type SyslogWriter struct {
+	mtx      sync.Mutex
+	conn     net.Conn
+	hostname string
+	appName  string
+	procID   string
+}
+
+// TLSSyslogWriter connects to the syslog receiver at addr over TLS. certFile
+// and keyFile are the client certificate and key, for receivers that
+// authenticate clients; leave them empty otherwise. caFile is the CA
+// certificate the receiver's certificate is checked against; when it's empty
+// the system's roots are used. Problems with the certificates, or with the
+// receiver's, are reported right away.
+func TLSSyslogWriter(addr, certFile, keyFile, caFile string) (*SyslogWriter, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificate: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no CA certificates found in " + caFile)
+		}
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	return &SyslogWriter{
+		conn:     conn,
+		hostname: syslogName(hostname, 255),
+		appName:  syslogName(filepath.Base(os.Args[0]), 48),
+		procID:   strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+// Write sends p as a message, without a trailing newline.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	if err := w.send(syslogInfo, time.Now(), "-", "-", msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteEntry implements EntryWriter.
+func (w *SyslogWriter) WriteEntry(e Entry) error {
+	severity := syslogInfo
+	switch {
+	case e.Status >= 500:
+		severity = syslogError
+	case e.Status >= 400:
+		severity = syslogWarning
+	}
+
+	duration := e.Duration.String()
+	sd := "[babylogger@0" +
+		sdParam("method", e.Method) +
+		sdParam("uri", e.URI) +
+		sdParam("status", strconv.Itoa(e.Status)) +
+		sdParam("duration", duration) + "]"
+	msg := fmt.Sprintf("%s %s %d %s", e.Method, e.URI, e.Status, duration)
+	return w.send(severity, e.Time, "access", sd, msg)
+}
+
+// Close closes the connection.
+func (w *SyslogWriter) Close() error {
+	return w.conn.Close()
+}
+
+// send sends a message with octet counting framing.
+func (w *SyslogWriter) send(severity int, t time.Time, msgID, sd, msg string) error {
+	m := fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s",
+		syslogFacility*8+severity,
+		t.Format("2006-01-02T15:04:05.000000Z07:00"),
+		w.hostname, w.appName, w.procID, msgID, sd, msg)
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	_, err := w.conn.Write([]byte(strconv.Itoa(len(m)) + " " + m))
+	return err
+}
+
+// sdParam formats a structured data parameter, escaping its value.
+func sdParam(name, value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	return " " + name + `="` + r.Replace(value) + `"`
+}
+
+// syslogName makes s fit for a header field: printable ASCII without spaces,
+// at most n characters, or - if empty.
+func syslogName(s string, n int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > n {
+		s = s[:n]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package babylogger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// LogEntry is what WithAccessLogTemplate's template is executed with. It
+// has all of Entry's fields, plus a few for convenience.
+type LogEntry struct {
+	Entry
+
+	// Latency is the time spent in the handler, the same as Duration.
+	Latency time.Duration
+
+	// Status is the response status, the same as Entry's, but typed so the
+	// color function knows to render it in the style for its class.
+	Status StatusCode
+
+	// StatusText is the text for Status, like OK. See WithStatusText.
+	StatusText string
+}
+
+// StatusCode is an HTTP response status. It's the type of LogEntry.Status.
+type StatusCode int
+
+// Attr returns the value of the attribute with the given key, or nil if
+// there isn't one, e.g. {{.Attr "request_id"}}.
+func (e LogEntry) Attr(key string) interface{} {
+	v, _ := e.value(key)
+	return v
+}
+
+// WithAccessLogTemplate logs each request as a single line rendered from a
+// text/template, once the response has been sent:
+//
+//	babylogger.WithAccessLogTemplate(`{{.Time.Format "15:04:05"}} {{color .Method}} {{.Path}} {{color .Status}} {{.Bytes}} {{color .Latency}}`)
+//
+// The template is executed with a LogEntry. The color function renders the
+// method, status or duration given to it in the theme's style for it, or as
+// is when colors are off. Statuses are recognized by their type, StatusCode,
+// so other ints, like .Bytes, are never colored as statuses. If executing the template fails, the usual
+// response line is logged instead, followed by the error as template_error.
+// WithAccessLogTemplate panics if the template doesn't parse. It only
+// affects the pretty format.
+func WithAccessLogTemplate(text string) Option {
+	return func(l *Logger) {
+		l.accessTemplate = text
+	}
+}
+
+// parseAccessTemplate parses the template set with WithAccessLogTemplate.
+func (l *Logger) parseAccessTemplate() error {
+	t, err := template.New("access").Funcs(template.FuncMap{
+		"color": l.colorize,
+	}).Parse(l.accessTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing access log template: %v", err)
+	}
+	l.accessTmpl = t
+	return nil
+}
+
+// colorize renders a method, status or duration in its style.
+func (l *Logger) colorize(v interface{}) string {
+	t := l.styles()
+	switch v := v.(type) {
+	case StatusCode:
+		return l.statusStyle(int(v)).Render(strconv.Itoa(int(v)))
+	case time.Duration:
+		return l.durationStyle(t, v).Render(v.String())
+	case string:
+		return l.methodStyle(t, v).Render(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// templateLine renders the line WithAccessLogTemplate logs for an entry.
+func (l *Logger) templateLine(e *Entry) string {
+	var b strings.Builder
+	err := l.accessTmpl.Execute(&b, LogEntry{
+		Entry:      *e,
+		Latency:    e.Duration,
+		Status:     StatusCode(e.Status),
+		StatusText: l.statusText(e.Status),
+	})
+	if err != nil {
+		return l.responseLine(e) + l.renderAttrs([]Attr{{"template_error", err.Error()}})
+	}
+	return b.String()
+}
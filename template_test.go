@@ -0,0 +1,17 @@
+package babylogger
+
+import "testing"
+
+func TestAccessLogTemplateColors(t *testing.T) {
+	theme := DefaultTheme().renderer(colorRenderer())
+	l := New(WithTheme(theme), WithAccessLogTemplate(`{{.Status}} {{color .Status}} {{color .Method}} {{color .Bytes}}`))
+
+	got := l.templateLine(&Entry{Status: 404, Method: "GET", Bytes: 500})
+	want := "404 " + theme.Status4xx.Render("404") + " " + theme.Method.Render("GET") + " 500"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if theme.Status4xx.Render("404") == "404" {
+		t.Fatal("status style renders without color")
+	}
+}
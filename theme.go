@@ -0,0 +1,155 @@
+package babylogger
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the set of styles used by the pretty format.
+type Theme struct {
+	Method    lipgloss.Style // request method
+	URI       lipgloss.Style // request URI
+	Address   lipgloss.Style // client address
+	Status2xx lipgloss.Style // 1xx and 2xx statuses
+	Status3xx lipgloss.Style // 3xx statuses
+	Status4xx lipgloss.Style // 4xx statuses
+	Status429 lipgloss.Style // rate limited responses
+	Status5xx lipgloss.Style // 5xx statuses
+	Bytes     lipgloss.Style // response size
+	Duration  lipgloss.Style // time spent in the handler
+	Subtle    lipgloss.Style // arrows and extra fields
+}
+
+// DefaultTheme returns the default Theme. Each color has a distinct value for
+// light and dark backgrounds: on light backgrounds we use darker, more
+// saturated shades so everything stays legible against white, and on dark
+// backgrounds lighter shades that don't get lost in black. Rough contrast
+// notes are included against white (light) and black (dark) backgrounds.
+//
+// Every call returns new styles, so there's no package-level state shared
+// between Loggers, and changing one Logger's theme never affects another.
+func DefaultTheme() Theme {
+	// #444444 on white, #949494 on black: both around 7:1
+	timeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "246"})
+
+	// Deliberately low contrast, but still readable: #808080 on white is
+	// about 3.9:1; #a8a8a8 on black about 8:1
+	subtleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "244", Dark: "248"})
+
+	return Theme{
+		// #5f5fd7 on white is about 5:1; #8787ff on black about 6:1
+		Method: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "62", Dark: "105"}),
+
+		URI:     timeStyle.Copy(),
+		Address: subtleStyle.Copy(),
+
+		// #008700 on white is about 4.6:1; #00ff87 on black about 15:1
+		Status2xx: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "48"}),
+
+		// #af5f00 on white is about 5:1; #d7ff87 on black about 17:1
+		Status3xx: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "192"}),
+
+		// #005faf on white is about 6.5:1; #5fd7d7 on black about 12:1
+		Status4xx: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "25", Dark: "80"}),
+
+		// #d75f00 on white is about 3.8:1; #ffaf00 on black about 12:1
+		Status429: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "166", Dark: "214"}),
+
+		// #d70000 on white is about 5:1; #ff5f87 on black about 7:1
+		Status5xx: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "204"}),
+
+		Bytes:    subtleStyle.Copy(),
+		Duration: timeStyle,
+		Subtle:   subtleStyle,
+	}
+}
+
+// WithTheme sets the styles used by the pretty format.
+func WithTheme(t Theme) Option {
+	return func(l *Logger) {
+		l.theme = t
+	}
+}
+
+// renderer returns a copy of the theme whose styles render with r.
+func (t Theme) renderer(r *lipgloss.Renderer) Theme {
+	t.Method = t.Method.Copy().Renderer(r)
+	t.URI = t.URI.Copy().Renderer(r)
+	t.Address = t.Address.Copy().Renderer(r)
+	t.Status2xx = t.Status2xx.Copy().Renderer(r)
+	t.Status3xx = t.Status3xx.Copy().Renderer(r)
+	t.Status4xx = t.Status4xx.Copy().Renderer(r)
+	t.Status429 = t.Status429.Copy().Renderer(r)
+	t.Status5xx = t.Status5xx.Copy().Renderer(r)
+	t.Bytes = t.Bytes.Copy().Renderer(r)
+	t.Duration = t.Duration.Copy().Renderer(r)
+	t.Subtle = t.Subtle.Copy().Renderer(r)
+	return t
+}
+
+// statusStyle returns the style for rendering a given status code.
+func (l *Logger) statusStyle(code int) lipgloss.Style {
+	t := l.styles()
+	if l.rateLimited(code) {
+		return t.Status429
+	}
+	switch class := l.classify(code); class.Name {
+	case ClassSuccess.Name:
+		return t.Status2xx
+	case ClassRedirect.Name:
+		return t.Status3xx
+	case ClassClientError.Name:
+		return t.Status4xx
+	case ClassServerError.Name:
+		return t.Status5xx
+	default:
+		if t == &l.plain {
+			return lipgloss.NewStyle()
+		}
+		return class.Style
+	}
+}
+
+// PreviewTheme writes sample log lines rendered with a Theme to w: a request
+// line for each common method and a response line for each status class.
+// It's handy for tuning a custom theme. Colors are rendered according to
+// what w supports, so output to a file or pipe will be plain.
+func PreviewTheme(w io.Writer, t Theme) {
+	l := New(WithTheme(t.renderer(lipgloss.NewRenderer(w))))
+
+	methods := []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}
+	for _, m := range methods {
+		fmt.Fprintln(w, l.requestLine(&Entry{
+			Method:     m,
+			URI:        "/preview",
+			RemoteAddr: "127.0.0.1",
+		}))
+	}
+
+	statuses := []int{
+		http.StatusOK, http.StatusMovedPermanently, http.StatusNotFound,
+		http.StatusTooManyRequests, http.StatusInternalServerError,
+	}
+	for i, code := range statuses {
+		fmt.Fprintln(w, l.responseLine(&Entry{
+			Status:   code,
+			Bytes:    1 << (10 + i),
+			Duration: time.Duration(i+1) * 1337 * time.Microsecond,
+		}))
+	}
+}
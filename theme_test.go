@@ -0,0 +1,97 @@
+package babylogger
+
+import (
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// luminance returns the relative luminance of an ANSI 256 color, as defined
+// by WCAG.
+func luminance(color string) float64 {
+	c := termenv.ConvertToRGB(termenv.ANSI256.Color(color))
+	lin := func(v float64) float64 {
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*lin(c.R) + 0.7152*lin(c.G) + 0.0722*lin(c.B)
+}
+
+// contrast returns the WCAG contrast ratio of two luminances.
+func contrast(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return (a + 0.05) / (b + 0.05)
+}
+
+// The default palette used to have the same color on light and dark
+// backgrounds for several styles, which all but disappeared on light
+// terminals. Before and after, against white (light) and black (dark):
+//
+//	style      before             after
+//	Duration   240/240 7.1:1 3.0:1  238/246 9.7:1 6.9:1
+//	Method     62/62   5.1:1 4.1:1  62/105  5.1:1 6.9:1
+//	Status2xx  35/48   2.9:1 16:1   28/48   4.7:1 16:1
+//	Status3xx  208/192 2.4:1 19:1   130/192 4.7:1 19:1
+//	Status4xx  39/86   2.4:1 17:1   25/80   6.4:1 12:1
+//	Status429  214/214 1.8:1 11:1   166/214 3.8:1 11:1
+//	Status5xx  203/204 3.0:1 7.2:1  160/204 5.4:1 7.2:1
+//	Subtle     250/250 1.9:1 11:1   244/248 3.9:1 8.8:1
+//
+// Every style now needs at least 3:1 against the background it's meant for.
+func TestDefaultThemeContrast(t *testing.T) {
+	const minContrast = 3
+	white, black := luminance("15"), luminance("0")
+
+	theme := DefaultTheme()
+	styles := map[string]lipgloss.Style{
+		"Method":    theme.Method,
+		"URI":       theme.URI,
+		"Address":   theme.Address,
+		"Status2xx": theme.Status2xx,
+		"Status3xx": theme.Status3xx,
+		"Status4xx": theme.Status4xx,
+		"Status429": theme.Status429,
+		"Status5xx": theme.Status5xx,
+		"Bytes":     theme.Bytes,
+		"Duration":  theme.Duration,
+		"Subtle":    theme.Subtle,
+	}
+
+	for name, style := range styles {
+		color, ok := style.GetForeground().(lipgloss.AdaptiveColor)
+		if !ok {
+			t.Errorf("%s: foreground isn't an AdaptiveColor", name)
+			continue
+		}
+		if color.Light == color.Dark {
+			t.Errorf("%s: same color %s on light and dark backgrounds", name, color.Light)
+		}
+		if c := contrast(luminance(color.Light), white); c < minContrast {
+			t.Errorf("%s: light color %s has contrast %.1f:1 against white", name, color.Light, c)
+		}
+		if c := contrast(luminance(color.Dark), black); c < minContrast {
+			t.Errorf("%s: dark color %s has contrast %.1f:1 against black", name, color.Dark, c)
+		}
+
+		for _, dark := range []bool{false, true} {
+			r := lipgloss.NewRenderer(io.Discard)
+			r.SetColorProfile(termenv.ANSI256)
+			r.SetHasDarkBackground(dark)
+			want := color.Light
+			if dark {
+				want = color.Dark
+			}
+			if got := style.Copy().Renderer(r).Render("x"); !strings.Contains(got, "38;5;"+want+"m") {
+				t.Errorf("%s: rendered %q with dark background %v, want color %s", name, got, dark, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package babylogger
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithRequestTimeout sets a per-request timeout. The given function is called
+// for every request and returns how long that request may take; returning 0
+// means no timeout. This is useful for giving public endpoints shorter
+// timeouts than internal ones, for example.
+//
+// The timeout is applied by wrapping the request's context with
+// context.WithTimeout before passing it to the next handler, so handlers must
+// respect context cancellation for it to have an effect. The applied timeout
+// is logged on the request line as timeout_applied=5s, and requests that ran
+// past it are logged with timed_out=true on the response line.
+func WithRequestTimeout(fn func(r *http.Request) time.Duration) Option {
+	return func(l *Logger) {
+		l.requestTimeout = fn
+	}
+}
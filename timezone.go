@@ -0,0 +1,62 @@
+package babylogger
+
+import (
+	"log"
+	"time"
+)
+
+// WithTimezone renders timestamps in the given location, like one returned
+// by time.LoadLocation, instead of the local one. It applies to the time
+// prefix of pretty lines, the time field of JSON lines and the time of the
+// Entry handed to EntryWriters. ECS timestamps are always in UTC.
+//
+// Pretty lines then get their time prefix from Babylogger rather than the
+// log package, formatted as the logger's flags say (log.Ldate, log.Ltime and
+// log.Lmicroseconds) and with its prefix, but in loc, even with log.LUTC.
+// WithTimezone and WithUTC override each other; the last one given wins.
+func WithTimezone(loc *time.Location) Option {
+	return func(l *Logger) {
+		l.location = loc
+	}
+}
+
+// WithUTC renders timestamps in UTC. See WithTimezone.
+func WithUTC() Option {
+	return WithTimezone(time.UTC)
+}
+
+// now returns the current time in the configured location.
+func (l *Logger) now() time.Time {
+	if l.location != nil {
+		return time.Now().In(l.location)
+	}
+	return time.Now()
+}
+
+// logLine formats a pretty line like logger would, but with the time in the
+// configured location.
+func (l *Logger) logLine(logger *log.Logger, line string) []byte {
+	flags := logger.Flags()
+	var b []byte
+	if flags&log.Lmsgprefix == 0 {
+		b = append(b, logger.Prefix()...)
+	}
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 {
+		now := l.now()
+		if flags&log.Ldate != 0 {
+			b = now.AppendFormat(b, "2006/01/02 ")
+		}
+		if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+			b = now.AppendFormat(b, "15:04:05")
+			if flags&log.Lmicroseconds != 0 {
+				b = now.AppendFormat(b, ".000000")
+			}
+			b = append(b, ' ')
+		}
+	}
+	if flags&log.Lmsgprefix != 0 {
+		b = append(b, logger.Prefix()...)
+	}
+	b = append(b, line...)
+	return append(b, '\n')
+}
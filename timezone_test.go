@@ -0,0 +1,96 @@
+package babylogger
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimezoneOffset(t *testing.T) {
+	zones := []*time.Location{time.FixedZone("UTC-5", -5*60*60), time.FixedZone("UTC+5:30", 5*60*60+30*60)}
+	if ny, err := time.LoadLocation("America/New_York"); err == nil {
+		zones = append(zones, ny)
+	}
+
+	for _, loc := range zones {
+		var entry Entry
+		l, out := newTestLogger(WithFormat(JSON), WithTimezone(loc), WithEntryWriter(entryWriterFunc(func(e Entry) error {
+			entry = e
+			return nil
+		})))
+		serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+
+		var line struct {
+			Time time.Time `json:"time"`
+		}
+		if err := json.Unmarshal([]byte(out.String()), &line); err != nil {
+			t.Fatalf("%s: %v", loc, err)
+		}
+		_, want := time.Now().In(loc).Zone()
+		if _, got := line.Time.Zone(); got != want {
+			t.Errorf("%s: logged time %v has offset %d, want %d", loc, line.Time, got, want)
+		}
+		if entry.Time.Location() != loc {
+			t.Errorf("%s: entry time is in %s", loc, entry.Time.Location())
+		}
+	}
+}
+
+func TestTimezonePrettyPrefix(t *testing.T) {
+	loc := time.FixedZone("UTC+14", 14*60*60)
+	l, out := newTestLogger(WithTimezone(loc))
+	serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+
+	want := time.Now().In(loc).Format("2006/01/02 15:")
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("line %q doesn't start with %q", out.String(), want)
+	}
+}
+
+func TestTimezoneLogFlags(t *testing.T) {
+	loc := time.FixedZone("UTC+14", 14*60*60)
+	hour := `\d{4}/\d\d/\d\d ` + time.Now().In(loc).Format("15") + `:\d\d:\d\d`
+	tests := []struct {
+		prefix string
+		flags  int
+		want   string
+	}{
+		{"app: ", log.LstdFlags, `^app: ` + hour + ` <- GET /`},
+		{"app: ", log.LstdFlags | log.Lmicroseconds | log.Lmsgprefix, `^` + hour + `\.\d{6} app: <- GET /`},
+		{"", log.Ltime, `^\d\d:\d\d:\d\d <- GET /`},
+		{"app: ", 0, `^app: <- GET /`},
+	}
+	for _, tt := range tests {
+		out := new(syncBuffer)
+		prefix, flags, w := log.Prefix(), log.Flags(), log.Writer()
+		log.SetOutput(out)
+		log.SetPrefix(tt.prefix)
+		log.SetFlags(tt.flags)
+
+		l := New(WithNoColor(), WithTimezone(loc))
+		serveTest(l, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+
+		log.SetOutput(w)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+
+		if got := out.String(); !regexp.MustCompile(tt.want).MatchString(got) {
+			t.Errorf("prefix %q, flags %d: line %q doesn't match %q", tt.prefix, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestTimezoneLastWins(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	if l := New(WithTimezone(loc), WithUTC()); l.now().Location() != time.UTC {
+		t.Errorf("WithUTC after WithTimezone: got %s", l.now().Location())
+	}
+	if l := New(WithUTC(), WithTimezone(loc)); l.now().Location() != loc {
+		t.Errorf("WithTimezone after WithUTC: got %s", l.now().Location())
+	}
+}
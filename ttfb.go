@@ -0,0 +1,11 @@
+package babylogger
+
+// WithTTFB logs the time to first byte, from when the request came in to the
+// handler's first write, alongside the total time, like ttfb=12ms total=45ms.
+// A high TTFB points to slow processing, while a total much higher than the
+// TTFB points to a slow transfer. Responses without a body don't get a TTFB.
+func WithTTFB() Option {
+	return func(l *Logger) {
+		l.ttfb = true
+	}
+}
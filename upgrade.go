@@ -0,0 +1,50 @@
+package babylogger
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WithUpgradeLogging logs protocol upgrades, like WebSocket connections
+// being established, which are otherwise logged as plain requests. For a 101
+// Switching Protocols response, the protocol switched to is logged from the
+// response's Upgrade header, along with the WebSocket subprotocol, if one
+// was negotiated:
+//
+//	upgrade_to=websocket upgrade_success=true websocket_protocol=graphql-ws
+//
+// Handlers that take over the connection with Hijack to send the 101
+// response themselves, as most WebSocket libraries do, are logged the same
+// way, with the protocol from the request's Upgrade header. Requests asking
+// for an upgrade that don't get one are logged with upgrade_success=false.
+func WithUpgradeLogging() Option {
+	return func(l *Logger) {
+		l.upgradeLogging = true
+	}
+}
+
+// addUpgrade logs the outcome of a request for a protocol upgrade, if it is
+// one.
+func addUpgrade(r *http.Request, w *logWriter, e *Entry) {
+	h := w.Header()
+	switch {
+	case w.code == http.StatusSwitchingProtocols:
+		to := h.Get("Upgrade")
+		if to == "" {
+			to = r.Header.Get("Upgrade")
+		}
+		e.add("upgrade_to", strings.ToLower(to))
+		e.add("upgrade_success", true)
+		if p := h.Get("Sec-WebSocket-Protocol"); p != "" {
+			e.add("websocket_protocol", p)
+		}
+
+	case r.Header.Get("Upgrade") != "" && w.hijacked():
+		e.add("upgrade_to", strings.ToLower(r.Header.Get("Upgrade")))
+		e.add("upgrade_success", true)
+
+	case r.Header.Get("Upgrade") != "":
+		e.add("upgrade_to", strings.ToLower(r.Header.Get("Upgrade")))
+		e.add("upgrade_success", false)
+	}
+}
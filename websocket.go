@@ -0,0 +1,87 @@
+package babylogger
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithWebSocketMode logs a second line for requests whose connection the
+// handler hijacks, as WebSocket libraries do, once the connection is closed:
+//
+//	~> GET /ws session_closed session_duration=12m3.5s bytes_read=5120 bytes_written=88832
+//
+// The response line of a hijacked request is logged as soon as the handler
+// returns, which for a long-lived socket is right after the handshake, so
+// its duration says nothing about the session. The second line has the
+// duration of the whole session and the bytes read from and written to the
+// connection.
+//
+// The connection is detected as closed when the handler, or the library it
+// handed the connection to, calls its Close method, so connections that are
+// never closed are never logged. Hijacked connections are wrapped to count
+// bytes and catch the Close, so type assertions on them, like to
+// *net.TCPConn, no longer work. Bytes that go through the bufio.ReadWriter
+// Hijack returns along with the connection aren't counted.
+func WithWebSocketMode() Option {
+	return func(l *Logger) {
+		l.webSocketMode = true
+	}
+}
+
+// sessionConn is a hijacked connection that counts the bytes going through
+// it and logs the session once it's closed.
+type sessionConn struct {
+	net.Conn
+	read, written int64 // accessed atomically
+	closeOnce     sync.Once
+	onClose       func(read, written int64)
+}
+
+func (c *sessionConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	atomic.AddInt64(&c.read, int64(n))
+	return n, err
+}
+
+func (c *sessionConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	atomic.AddInt64(&c.written, int64(n))
+	return n, err
+}
+
+// CloseWrite shuts down the writing side of the connection, if the
+// connection beneath supports it. Otherwise it closes the connection, like
+// closeWrite does.
+func (c *sessionConn) CloseWrite() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return c.Close()
+}
+
+func (c *sessionConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		c.onClose(atomic.LoadInt64(&c.read), atomic.LoadInt64(&c.written))
+	})
+	return err
+}
+
+// sessionHook returns the hook that wraps a request's hijacked connection,
+// logging the session when it's closed.
+func (l *Logger) sessionHook(e *Entry, start time.Time) func(net.Conn) net.Conn {
+	return func(conn net.Conn) net.Conn {
+		return &sessionConn{
+			Conn: conn,
+			onClose: func(read, written int64) {
+				l.logEvent(e, "session_closed",
+					Attr{"session_duration", time.Since(start)},
+					Attr{"bytes_read", read},
+					Attr{"bytes_written", written},
+				)
+			},
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package babylogger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// hijackRecorder is a ResponseRecorder whose connection can be hijacked.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
+
+func TestWebSocketModeSession(t *testing.T) {
+	l, out := newTestLogger(WithWebSocketMode())
+	server, client := net.Pipe()
+	done := make(chan struct{})
+
+	// Like a WebSocket library, the handler hands the connection off to a
+	// goroutine and returns right after the handshake.
+	h := func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			close(done)
+			return
+		}
+		go func() {
+			defer close(done)
+			conn.Write([]byte("hello"))
+			buf := make([]byte, 3)
+			io.ReadFull(conn, buf)
+			conn.Close()
+			conn.Close()
+		}()
+	}
+	l.Middleware(http.HandlerFunc(h)).ServeHTTP(
+		&hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server},
+		httptest.NewRequest("GET", "/ws", nil),
+	)
+
+	if got := out.String(); strings.Contains(got, "session_closed") {
+		t.Fatalf("session logged before the connection was closed:\n%s", got)
+	}
+
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(client, buf); err != nil {
+		t.Fatal(err)
+	}
+	client.Write([]byte("hey"))
+	<-done
+	client.Close()
+
+	var sessions []string
+	for _, line := range lines(out) {
+		if strings.Contains(line, "session_closed") {
+			sessions = append(sessions, line)
+		}
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("got %d session lines, want 1:\n%s", len(sessions), out.String())
+	}
+	for _, s := range []string{"GET /ws", "session_duration=", "bytes_read=3", "bytes_written=5"} {
+		if !strings.Contains(sessions[0], s) {
+			t.Errorf("%q missing from %q", s, sessions[0])
+		}
+	}
+}
+
+// halfCloser is a connection that records CloseWrite calls.
+type halfCloser struct {
+	net.Conn
+	closedWrite bool
+}
+
+func (c *halfCloser) CloseWrite() error {
+	c.closedWrite = true
+	return nil
+}
+
+func TestSessionConnCloseWrite(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	var closed int
+	hc := &halfCloser{Conn: server}
+	closeWrite(&sessionConn{Conn: hc, onClose: func(read, written int64) { closed++ }})
+	if !hc.closedWrite {
+		t.Error("CloseWrite not forwarded to the connection")
+	}
+	if closed != 0 {
+		t.Error("session logged on CloseWrite")
+	}
+
+	closeWrite(&sessionConn{Conn: server, onClose: func(read, written int64) { closed++ }})
+	if closed != 1 {
+		t.Error("connection without CloseWrite not closed")
+	}
+}